@@ -0,0 +1,262 @@
+// Package config binds the fields of structs to values read from
+// environment variables, flags and files, so that a container can
+// provide config structs like any other type:
+//
+//	type DBConfig struct {
+//		URL     string        `config:"db.url,required"`
+//		Timeout time.Duration `config:"db.timeout" default:"5s"`
+//	}
+//
+//	container.Provide(config.Struct[DBConfig](
+//		config.Flags(flag.CommandLine),
+//		config.Env("APP"),
+//		config.JSONFile("config.json"),
+//	))
+package config
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+)
+
+// The tags of the fields of config structs.
+const (
+	configTag  = "config"
+	defaultTag = "default"
+
+	// skipTag is the value of the config tag of fields which are
+	// not bound.
+	skipTag = "-"
+
+	// requiredModifier marks a field whose key must have a value.
+	requiredModifier = "required"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Validator is implemented by config structs which check their values
+// once they are bound.
+type Validator interface {
+	Validate() error
+}
+
+// Struct returns a constructor of T, which can be passed to Provide, and
+// which binds the fields of T from the sources with Bind. Errors binding
+// or validating the struct are returned by the constructor, and so are
+// reported by the container.
+func Struct[T any](sources ...Source) func() (T, error) {
+	return func() (T, error) {
+		var cfg T
+		if err := Bind(&cfg, sources...); err != nil {
+			return cfg, err
+		}
+		return cfg, nil
+	}
+}
+
+// Bind binds the fields of the struct pointed to by target which are
+// tagged with `config:"key"` to the value of their key. The sources are
+// consulted in order, and the first which has a value for the key is
+// used. Otherwise, the field is set to the value of its default tag, if
+// it has one, and an error is returned if it is tagged as required:
+//
+//	type Config struct {
+//		URL     string        `config:"db.url,required"`
+//		Timeout time.Duration `config:"db.timeout" default:"5s"`
+//		Hosts   []string      `config:"db.hosts" default:"a,b"`
+//		Cache   CacheConfig   `config:"cache"`
+//	}
+//
+// The keys of the fields of a nested struct are prefixed with the key
+// of the struct, and embedded structs without a tag are bound as if
+// their fields belonged to the outer struct. If the struct implements
+// Validator, it is validated once bound.
+func Bind(target any, sources ...Source) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() ||
+		v.Elem().Kind() != reflect.Struct {
+		return errors.Newf(invalidTargetErrMsg, target)
+	}
+
+	if err := bindStruct(v.Elem(), "", sources); err != nil {
+		return err
+	}
+
+	if validator, ok := target.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return errors.Newf(validationErrMsg, v.Elem().Type(), err)
+		}
+	}
+	return nil
+}
+
+// bindStruct binds the fields of the struct value, prefixing their keys
+// with the prefix if it is not empty.
+func bindStruct(v reflect.Value, prefix string, sources []Source) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(configTag)
+		if !ok && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := bindStruct(v.Field(i), prefix, sources); err != nil {
+				return err
+			}
+			continue
+		} else if !ok || !field.IsExported() {
+			continue
+		}
+
+		key, modifiers, _ := strings.Cut(tag, ",")
+		if key = strings.TrimSpace(key); key == skipTag {
+			continue
+		} else if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		required := false
+		if modifiers != "" {
+			for _, modifier := range strings.Split(modifiers, ",") {
+				switch modifier = strings.TrimSpace(modifier); modifier {
+				case requiredModifier:
+					required = true
+				default:
+					return errors.Newf(
+						unknownTagModifierErrMsg, modifier, field.Name,
+					)
+				}
+			}
+		}
+
+		// Nested structs are bound field by field, unless they can be
+		// decoded from a single value.
+		if isNested(field.Type) {
+			if err := bindStruct(v.Field(i), key, sources); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, found, err := lookup(key, sources)
+		if err != nil {
+			return err
+		}
+		if !found {
+			value, found = field.Tag.Lookup(defaultTag)
+		}
+		if !found {
+			if required {
+				return errors.Newf(
+					missingValueErrMsg, ErrMissingValue, key, field.Name,
+				)
+			}
+			continue
+		}
+
+		if err = setValue(v.Field(i), value); err != nil {
+			return errors.Newf(
+				invalidValueErrMsg, ErrInvalidValue, value, key, field.Name, err,
+			)
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of the key from the first source which has
+// one.
+func lookup(key string, sources []Source) (string, bool, error) {
+	for _, source := range sources {
+		value, ok, err := source.Lookup(key)
+		if err != nil || ok {
+			return value, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+// isNested returns true if the type is a struct whose fields are bound
+// separately.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setValue decodes the string into the value.
+func setValue(v reflect.Value, s string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).
+			UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		// Lists are comma-separated, and an empty value is an empty
+		// list.
+		var elems []string
+		if s = strings.TrimSpace(s); s != "" {
+			elems = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := setValue(slice.Index(i), strings.TrimSpace(elem)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return errors.Newf(unsupportedTypeErrMsg, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import "github.com/skjdfhkskjds/depinject/internal/errors"
+
+var (
+	// ErrMissingValue is returned when no source has a value for a
+	// required key, and the key has no default.
+	ErrMissingValue = errors.New("missing config value")
+
+	// ErrInvalidValue is returned when the value of a key cannot be
+	// decoded into the type of its field.
+	ErrInvalidValue = errors.New("invalid config value")
+)
+
+const (
+	invalidTargetErrMsg = "config target must be a non-nil pointer to a " +
+		"struct, got %T"
+	missingValueErrMsg       = "%w for key %q of field %s"
+	invalidValueErrMsg       = "%w %q for key %q of field %s: %w"
+	unsupportedTypeErrMsg    = "unsupported type %s"
+	unknownTagModifierErrMsg = "unknown config tag modifier %q on field %s"
+	validationErrMsg         = "invalid %s: %w"
+	readFileErrMsg           = "failed to read config file %s: %w"
+	notAValueErrMsg          = "key %q of config file %s is an object, " +
+		"not a value"
+)
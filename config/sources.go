@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+)
+
+// A Source provides the values of config keys, which are dot-separated
+// paths such as "db.url".
+type Source interface {
+	// Lookup returns the value of the key, and whether the source has
+	// a value for it.
+	Lookup(key string) (string, bool, error)
+}
+
+// SourceFunc adapts a function to a Source.
+type SourceFunc func(key string) (string, bool, error)
+
+func (f SourceFunc) Lookup(key string) (string, bool, error) {
+	return f(key)
+}
+
+// Env returns a source which reads keys from environment variables. The
+// variable for a key is the key in upper case, with dots and dashes
+// replaced by underscores, and prefixed by the prefix and an underscore
+// if the prefix is not empty. For instance, with the prefix "APP", the
+// key "db.url" is read from APP_DB_URL.
+func Env(prefix string) Source {
+	replacer := strings.NewReplacer(".", "_", "-", "_")
+	return SourceFunc(func(key string) (string, bool, error) {
+		name := strings.ToUpper(replacer.Replace(key))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		value, ok := os.LookupEnv(name)
+		return value, ok, nil
+	})
+}
+
+// Flags returns a source which reads keys from the flags of the set
+// which were set on the command line, so that the defaults of the
+// config's fields apply otherwise. The flag for a key is named after
+// the key, such as -db.url.
+func Flags(fs *flag.FlagSet) Source {
+	return SourceFunc(func(key string) (string, bool, error) {
+		var (
+			value string
+			ok    bool
+		)
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == key {
+				value, ok = f.Value.String(), true
+			}
+		})
+		return value, ok, nil
+	})
+}
+
+// A Decoder decodes the contents of a config file into a tree of values,
+// in which nested objects are map[string]any values and lists are []any
+// values, as decoded by encoding/json.
+type Decoder func(data []byte) (map[string]any, error)
+
+// JSON is the Decoder of JSON files.
+func JSON(data []byte) (map[string]any, error) {
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// File returns a source which reads keys from the file at the path,
+// decoded with the decoder. A key is the path through the file's nested
+// objects to its value, and lists are read as comma-separated values.
+// The file is read the first time a key is looked up.
+func File(path string, decode Decoder) Source {
+	var (
+		once   sync.Once
+		values map[string]any
+		err    error
+	)
+	return SourceFunc(func(key string) (string, bool, error) {
+		once.Do(func() {
+			var data []byte
+			if data, err = os.ReadFile(path); err == nil {
+				values, err = decode(data)
+			}
+			if err != nil {
+				err = errors.Newf(readFileErrMsg, path, err)
+			}
+		})
+		if err != nil {
+			return "", false, err
+		}
+
+		var value any = values
+		for _, part := range strings.Split(key, ".") {
+			object, ok := value.(map[string]any)
+			if !ok {
+				return "", false, nil
+			}
+			if value, ok = object[part]; !ok {
+				return "", false, nil
+			}
+		}
+
+		switch value.(type) {
+		case nil:
+			return "", false, nil
+		case map[string]any:
+			return "", false, errors.Newf(notAValueErrMsg, key, path)
+		default:
+			return formatValue(value), true, nil
+		}
+	})
+}
+
+// JSONFile returns a source which reads keys from the JSON file at the
+// path.
+func JSONFile(path string) Source {
+	return File(path, JSON)
+}
+
+// formatValue formats a decoded value as it would be written in an
+// environment variable or flag.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		elems := make([]string, len(v))
+		for i, elem := range v {
+			elems[i] = formatValue(elem)
+		}
+		return strings.Join(elems, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
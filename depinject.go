@@ -1,6 +1,9 @@
 package depinject
 
 import (
+	"context"
+	"reflect"
+
 	depinject "github.com/skjdfhkskjds/depinject/internal/depinject"
 )
 
@@ -14,19 +17,92 @@ type (
 	//	container.Invoke(func(dep1, dep2, ...) {
 	//		// do something with the dependencies
 	//	})
+	//
+	// A container is safe for concurrent use by multiple goroutines.
 	Container = depinject.Container
 
 	// In is a sentinel type used to indicate that a struct is
 	// actually a container for various types that should be included
-	// in the constructor's argument list.
+	// in the constructor's argument list. Fields can be tagged with
+	// `name:"..."` or `group:"..."` to request a named value or every
+	// value in a group, and with `optional:"true"` to be left as the
+	// zero value if they have no providers.
 	In = depinject.In
 
 	// Out is a sentinel type used to indicate that a struct is
 	// actually a container for various types that should be included
-	// in the constructor's output list.
+	// in the constructor's output list. Fields can be tagged with
+	// `name:"..."` or `group:"..."` to provide a named value or to
+	// contribute to a group.
 	Out = depinject.Out
+
+	// ProviderInfo describes a provider registered in a container, as
+	// returned by the container's introspection methods.
+	ProviderInfo = depinject.ProviderInfo
+
+	// ConstructorError is returned when a constructor panics while the
+	// container is being resolved. It carries the panic value, its
+	// stack trace and the chain of constructors through which an
+	// invoked type depends on it.
+	ConstructorError = depinject.ConstructorError
+
+	// Module is a named part of a container, which constructors can be
+	// provided in to keep their private values to themselves.
+	Module = depinject.Module
+
+	// Annotation configures how the container treats the constructors
+	// it is provided alongside. Annotations can be passed to Provide
+	// in any position, and apply to every constructor in that call.
+	Annotation = depinject.Annotation
 )
 
+// Provider is a factory for values of type T. A constructor which
+// depends on a Provider[T] is not resolved after T's provider; instead,
+// it receives a function which returns T from the container when called.
+// Each call constructs T if it has not been yet, or anew on every call if
+// T's provider is transient. A plain func() (T, error) dependency is
+// treated the same way, unless a constructor provides it as is.
+type Provider[T any] func() (T, error)
+
+// Lazy is a deferred dependency on a value of type T, which is resolved
+// on the first call to Get. Unlike a Provider, a Lazy dependency is not
+// part of the container's dependency graph, so it can be used to break
+// a cycle between mutually dependent constructors. A cycle which is
+// still present when Get is called is returned as an error.
+type Lazy[T any] func() (T, error)
+
+// Get returns the value of type T from the container, constructing it
+// if it has not been yet.
+func (l Lazy[T]) Get() (T, error) {
+	return l()
+}
+
+// Factory is an assisted factory, which constructs values from a mix of
+// parameters known only at runtime and dependencies from the container.
+// F must be a function which returns a value and an error, such as
+// func(tenantID string) (*Client, error). Each of its parameters is
+// passed to the argument of the same type in the value's constructor,
+// while the constructor's remaining arguments are resolved from the
+// container whenever New is called.
+//
+// A constructor which is used by a Factory is only ever called through
+// it, and cannot be depended on directly.
+type Factory[F any] struct {
+	// New constructs a new value with the given parameters.
+	New F
+}
+
+// Struct returns a constructor for T, which must be a struct or a pointer
+// to a struct, to pass to Provide in place of a hand-written constructor.
+// The constructor depends on each of the struct's exported fields, which
+// can be tagged the same way as for Populate. Fields tagged with
+// `inject:"-"` are left as is.
+//
+//	container.Provide(depinject.Struct[*Server]())
+func Struct[T any]() any {
+	return depinject.Struct(reflect.TypeOf((*T)(nil)).Elem())
+}
+
 // Available functions from this package.
 var (
 	// NewContainer returns a new, valid container.
@@ -35,6 +111,15 @@ var (
 	// DefaultContainer returns a new container with the default options.
 	DefaultContainer = depinject.DefaultContainer
 
+	// ErrUnusedProviders is returned when building a container created
+	// with WithStrictUnused, or by CheckUnused, if a provider is not used
+	// by any of the given types.
+	ErrUnusedProviders = depinject.ErrUnusedProviders
+
+	// ErrContainerSealed is returned when registering a provider with a
+	// container which has been sealed.
+	ErrContainerSealed = depinject.ErrContainerSealed
+
 	// ===============================================================
 	//                            Options
 	// ===============================================================
@@ -42,23 +127,74 @@ var (
 	// WithLogger sets the logger to dump the container's info to.
 	WithLogger = depinject.WithLogger
 
-	// Instructs the container to enable the use of sentinel
-	// structs in constructor arguments and parses the struct's
-	// fields as constructor arguments.
+	// Deprecated: Sentinel structs in constructor arguments are
+	// parsed by default, so this option has no effect.
 	WithInSentinel = depinject.WithInSentinel
 
-	// Instructs the container to enable the use of sentinel
-	// structs in constructor outputs and parses the struct's
-	// fields as constructor outputs.
+	// Deprecated: Sentinel structs in constructor outputs are
+	// parsed by default, so this option has no effect.
 	WithOutSentinel = depinject.WithOutSentinel
 
+	// Instructs the container to not parse the fields of sentinel
+	// structs as constructor arguments and outputs.
+	WithoutSentinels = depinject.WithoutSentinels
+
+	// Instructs the container to seal itself once it is first resolved,
+	// rejecting any later providers.
+	WithAutoSeal = depinject.WithAutoSeal
+
+	// Instructs the container to fail to build if a provider is not
+	// used by any of the given types, of which there is at least one,
+	// regardless of the types invoked from the container.
+	WithStrictUnused = depinject.WithStrictUnused
+
+	// Sets the profiles which are active in the container, which
+	// constructors annotated with Profile are only provided in.
+	WithProfiles = depinject.WithProfiles
+
 	// Allows the container to match dependencies that are interfaces
 	// to types which are implementations of those interfaces.
 	WithInterfaceInference = depinject.WithInterfaceInference
 
 	// Allows the container to have multiple constructors with the same
 	// output type, and will process them as lists (slices or arrays).
+	// Named values are also collected into maps keyed by their names.
 	WithListInference = depinject.WithListInference
+
+	// Instructs the container to execute independent constructors
+	// concurrently, using at most the given number of workers.
+	WithParallelResolution = depinject.WithParallelResolution
+
+	// Instructs the container to not recover panics in constructors,
+	// letting them crash the program.
+	WithPanicPassthrough = depinject.WithPanicPassthrough
+
+	// Instructs the container to close every constructed value which
+	// implements io.Closer when the container is closed.
+	WithAutoClose = depinject.WithAutoClose
+
+	// ===============================================================
+	//                          Annotations
+	// ===============================================================
+
+	// Bounds the time a constructor is allowed to run for.
+	Timeout = depinject.Timeout
+
+	// Instructs the container to execute a constructor for every
+	// value it needs to inject, rather than once.
+	Transient = depinject.Transient
+
+	// Provides a constructor's values under the given name, which
+	// fields can request with the inject tag.
+	Name = depinject.Name
+
+	// Only provides a constructor if one of the given profiles is
+	// active in the container.
+	Profile = depinject.Profile
+
+	// Only makes a constructor's values visible to the module it is
+	// provided in, and to its submodules.
+	Private = depinject.Private
 )
 
 // Global container instance for users who would rather not
@@ -71,12 +207,37 @@ func Invoke(outputs ...any) error {
 	return c.Invoke(outputs...)
 }
 
+// InvokeContext invokes the given functions with the dependencies
+// injected from the global container instance, resolving the
+// container with the given context.
+func InvokeContext(ctx context.Context, outputs ...any) error {
+	return c.InvokeContext(ctx, outputs...)
+}
+
+// Populate sets the fields of the given struct pointer tagged with
+// `inject` to values from the global container instance.
+func Populate(target any) error {
+	return c.Populate(target)
+}
+
 // Provide provides the given constructors into the global container instance.
 func Provide(constructors ...any) error {
 	return c.Provide(constructors...)
 }
 
+// ProvideIf provides the given constructors into the global container
+// instance if the predicate returns true.
+func ProvideIf(predicate func() bool, constructors ...any) error {
+	return c.ProvideIf(predicate, constructors...)
+}
+
 // Supply supplies the given values into the global container instance.
 func Supply(values ...any) error {
 	return c.Supply(values...)
 }
+
+// Seal prevents any more providers from being registered with the
+// global container instance.
+func Seal() {
+	c.Seal()
+}
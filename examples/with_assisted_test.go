@@ -0,0 +1,95 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to construct values from parameters which are only known
+// at runtime, alongside dependencies from the container.
+//
+// In this case, TenantClient is constructed with a tenant ID and a
+// Registry. TenantPool depends on a Factory which takes the tenant ID,
+// while the Registry is resolved from the container.
+
+type Registry struct {
+	name string
+}
+
+type TenantClient struct {
+	tenantID string
+	registry *Registry
+}
+
+func NewTenantClient(registry *Registry, tenantID string) (*TenantClient, error) {
+	if tenantID == "" {
+		return nil, errors.New("empty tenant ID")
+	}
+	return &TenantClient{tenantID: tenantID, registry: registry}, nil
+}
+
+type TenantPool struct {
+	clients depinject.Factory[func(tenantID string) (*TenantClient, error)]
+}
+
+func NewTenantPool(
+	clients depinject.Factory[func(tenantID string) (*TenantClient, error)],
+) *TenantPool {
+	return &TenantPool{clients: clients}
+}
+
+func TestWithAssistedFactory(t *testing.T) {
+	container := depinject.NewContainer()
+
+	registry := &Registry{name: "main"}
+	testutils.RequireNoError(t, container.Supply(registry))
+	testutils.RequireNoError(t, container.Provide(NewTenantClient, NewTenantPool))
+
+	var pool *TenantPool
+	testutils.RequireNoError(t, container.Invoke(&pool))
+
+	// Every call constructs a new client for the given tenant.
+	client1, err := pool.clients.New("tenant-1")
+	testutils.RequireNoError(t, err)
+	client2, err := pool.clients.New("tenant-2")
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, client1.tenantID, "tenant-1")
+	testutils.RequireEquals(t, client2.tenantID, "tenant-2")
+	testutils.RequireTrue(t, client1.registry == registry)
+	testutils.RequireTrue(t, client2.registry == registry)
+
+	// Errors from the constructor are returned by the factory.
+	_, err = pool.clients.New("")
+	testutils.RequireError(t, err)
+}
+
+func TestWithAssistedFactoryUnmatchedParam(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Supply(&Registry{}))
+	testutils.RequireNoError(t, container.Provide(
+		NewTenantClient,
+		func(depinject.Factory[func(int) (*TenantClient, error)]) *TenantPool {
+			return &TenantPool{}
+		},
+	))
+
+	// The factory's parameters must match the constructor's arguments.
+	var pool *TenantPool
+	testutils.RequireError(t, container.Invoke(&pool))
+}
+
+func TestWithAssistedFactoryDirectDependency(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Supply(&Registry{}))
+	testutils.RequireNoError(t, container.Provide(NewTenantClient, NewTenantPool))
+
+	// Values which require runtime parameters cannot be invoked directly.
+	var client *TenantClient
+	testutils.RequireError(t, container.Invoke(&client))
+}
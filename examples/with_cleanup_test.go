@@ -0,0 +1,137 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to release the resources held by constructed values.
+//
+// Constructors can return a cleanup function (func() or func() error)
+// after their values, which the container runs when it is closed.
+// Values are always cleaned up before the values they depend on.
+
+type DB struct {
+	log *[]string
+}
+
+func NewDB(log *[]string) (*DB, func(), error) {
+	db := &DB{log: log}
+	return db, func() { *log = append(*log, "db") }, nil
+}
+
+type Cache struct{}
+
+func NewCache(db *DB) (*Cache, func() error) {
+	return &Cache{}, func() error {
+		*db.log = append(*db.log, "cache")
+		return nil
+	}
+}
+
+type Conn struct {
+	closed bool
+}
+
+func (c *Conn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type Pool struct {
+	closed bool
+}
+
+func (p *Pool) Close() error {
+	p.closed = true
+	return nil
+}
+
+func NewPool(_ *Conn) *Pool {
+	return &Pool{}
+}
+
+func TestWithCleanup(t *testing.T) {
+	container := depinject.NewContainer()
+
+	var log []string
+	testutils.RequireNoError(t, container.Supply(&log))
+	testutils.RequireNoError(t, container.Provide(NewDB, NewCache))
+
+	var cache *Cache
+	testutils.RequireNoError(t, container.Invoke(&cache))
+	testutils.RequireEmpty(t, log)
+
+	// The cache depends on the database, so it is cleaned up first.
+	testutils.RequireNoError(t, container.Close())
+	testutils.RequireEquals(t, []string{"cache", "db"}, log)
+
+	// Cleanups are only run once.
+	testutils.RequireNoError(t, container.Close())
+	testutils.RequireLen(t, log, 2)
+}
+
+func TestWithCleanupErrors(t *testing.T) {
+	container := depinject.NewContainer()
+
+	errFoo, errBar := errors.New("foo"), errors.New("bar")
+	testutils.RequireNoError(t, container.Provide(
+		func() (*Foo, func() error) {
+			return &Foo{}, func() error { return errFoo }
+		},
+		func(*Foo) (*Bar, func() error) {
+			return &Bar{}, func() error { return errBar }
+		},
+	))
+
+	var bar *Bar
+	testutils.RequireNoError(t, container.Invoke(&bar))
+
+	// Every cleanup runs, and all of their errors are returned.
+	err := container.Close()
+	testutils.RequireTrue(t, errors.Is(err, errFoo))
+	testutils.RequireTrue(t, errors.Is(err, errBar))
+}
+
+func TestWithAutoClose(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithAutoClose())
+
+	conn := &Conn{}
+	testutils.RequireNoError(t, container.Supply(conn))
+	testutils.RequireNoError(t, container.Provide(NewPool))
+
+	var pool *Pool
+	testutils.RequireNoError(t, container.Invoke(&pool))
+	testutils.RequireFalse(t, pool.closed)
+
+	// Destroying the container closes it. Supplied values are owned
+	// by the caller, so they are left open.
+	container.Destroy()
+	testutils.RequireTrue(t, pool.closed)
+	testutils.RequireFalse(t, conn.closed)
+}
+
+type ConnWithOut struct {
+	depinject.Out
+
+	Conn *Conn
+}
+
+func TestWithAutoCloseSuppliedOut(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithAutoClose())
+
+	// The fields of a supplied Out struct are owned by the caller too.
+	conn := &Conn{}
+	testutils.RequireNoError(t, container.Supply(ConnWithOut{Conn: conn}))
+	testutils.RequireNoError(t, container.Provide(NewPool))
+
+	var pool *Pool
+	testutils.RequireNoError(t, container.Invoke(&pool))
+	container.Destroy()
+	testutils.RequireTrue(t, pool.closed)
+	testutils.RequireFalse(t, conn.closed)
+}
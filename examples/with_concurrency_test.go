@@ -0,0 +1,98 @@
+package examples
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates that a container can be shared across
+// goroutines. These tests are most useful when run with the race
+// detector enabled (see `make test-race`).
+
+const numGoroutines = 50
+
+func TestConcurrentInvoke(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Supply(&Foo{}))
+	testutils.RequireNoError(t, container.Provide(NewBar, NewFooBar))
+
+	var wg sync.WaitGroup
+	results := make([]*FooBar, numGoroutines)
+	errs := make([]error, numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = container.Invoke(&results[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// Every invocation should receive the same singleton instance.
+	for i := 0; i < numGoroutines; i++ {
+		testutils.RequireNoError(t, errs[i])
+		testutils.RequireTrue(t, results[i] == results[0])
+	}
+}
+
+func TestConcurrentProvideAndInvoke(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	testutils.RequireNoError(t, container.Supply(&Foo{}))
+	testutils.RequireNoError(t, container.Provide(NewFooBarVariadic))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2*numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = container.Provide(NewBar)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			var fooBar *FooBar
+			errs[numGoroutines+i] = container.Invoke(&fooBar)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		testutils.RequireNoError(t, err)
+	}
+
+	// Once all providers are registered, every Bar is visible.
+	var bars []*Bar
+	testutils.RequireNoError(t, container.Invoke(&bars))
+	testutils.RequireLen(t, bars, numGoroutines)
+}
+
+type ConcurrentValue struct{ id int }
+
+func TestConcurrentInvokeSupplied(t *testing.T) {
+	container := depinject.NewContainer()
+
+	supplied := &ConcurrentValue{id: 1}
+	testutils.RequireNoError(t, container.Supply(supplied))
+
+	var wg sync.WaitGroup
+	results := make([]*ConcurrentValue, numGoroutines)
+	errs := make([]error, numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = container.Invoke(&results[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < numGoroutines; i++ {
+		testutils.RequireNoError(t, errs[i])
+		testutils.RequireTrue(t, results[i] == supplied)
+	}
+}
@@ -0,0 +1,171 @@
+package examples
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/config"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to provide config structs, whose fields
+// are bound from environment variables, flags and files, to the
+// constructors which depend on them.
+
+type PoolConfig struct {
+	Size int `config:"size" default:"4"`
+}
+
+type DBConfig struct {
+	URL     string        `config:"db.url,required"`
+	Timeout time.Duration `config:"db.timeout" default:"5s"`
+	Hosts   []string      `config:"db.hosts"`
+	Debug   bool          `config:"debug"`
+	Pool    PoolConfig    `config:"db.pool"`
+}
+
+var errNoScheme = errors.New("url has no scheme")
+
+func (c DBConfig) Validate() error {
+	if !strings.Contains(c.URL, "://") {
+		return errNoScheme
+	}
+	return nil
+}
+
+type ConfiguredDB struct {
+	config DBConfig
+}
+
+func NewConfiguredDB(config DBConfig) *ConfiguredDB {
+	return &ConfiguredDB{config: config}
+}
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	testutils.RequireNoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestWithConfig(t *testing.T) {
+	t.Setenv("APP_DB_URL", "postgres://env")
+	t.Setenv("APP_DEBUG", "true")
+
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags.String("db.url", "", "")
+	flags.Duration("db.timeout", time.Second, "")
+	testutils.RequireNoError(t, flags.Parse([]string{"-db.url=postgres://flag"}))
+
+	path := writeConfigFile(t, "config.json", `{
+		"db": {"url": "postgres://file", "hosts": ["a", "b"], "pool": {"size": 8}}
+	}`)
+
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		config.Struct[DBConfig](
+			config.Flags(flags),
+			config.Env("APP"),
+			config.JSONFile(path),
+		),
+		NewConfiguredDB,
+	))
+
+	var db *ConfiguredDB
+	testutils.RequireNoError(t, container.Invoke(&db))
+
+	// Earlier sources take precedence, and flags which were not set do
+	// not override the field's default.
+	testutils.RequireEquals(t, "postgres://flag", db.config.URL)
+	testutils.RequireEquals(t, 5*time.Second, db.config.Timeout)
+	testutils.RequireEquals(t, true, db.config.Debug)
+	testutils.RequireLen(t, db.config.Hosts, 2)
+	testutils.RequireEquals(t, "b", db.config.Hosts[1])
+	testutils.RequireEquals(t, 8, db.config.Pool.Size)
+}
+
+func TestWithConfigDecoder(t *testing.T) {
+	// Other formats are read with a decoder of their own, here one
+	// which reads lines of key=value pairs.
+	decodeLines := func(data []byte) (map[string]any, error) {
+		values := make(map[string]any)
+		for _, line := range strings.Split(string(data), "\n") {
+			if key, value, ok := strings.Cut(line, "="); ok {
+				values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+		return map[string]any{"db": values}, nil
+	}
+	path := writeConfigFile(t, "config.txt", "url = mysql://lines\n")
+
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		config.Struct[DBConfig](config.File(path, decodeLines)),
+		NewConfiguredDB,
+	))
+
+	var db *ConfiguredDB
+	testutils.RequireNoError(t, container.Invoke(&db))
+	testutils.RequireEquals(t, "mysql://lines", db.config.URL)
+	testutils.RequireEquals(t, 4, db.config.Pool.Size)
+}
+
+func TestWithConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr error
+	}{
+		{
+			name:    "missing required value",
+			wantErr: config.ErrMissingValue,
+		},
+		{
+			name: "invalid value",
+			env: map[string]string{
+				"APP_DB_URL":     "postgres://env",
+				"APP_DB_TIMEOUT": "soon",
+			},
+			wantErr: config.ErrInvalidValue,
+		},
+		{
+			name:    "failed validation",
+			env:     map[string]string{"APP_DB_URL": "localhost"},
+			wantErr: errNoScheme,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			container := depinject.NewContainer()
+			testutils.RequireNoError(t, container.Provide(
+				config.Struct[DBConfig](config.Env("APP")),
+				NewConfiguredDB,
+			))
+
+			// Errors binding the config are reported by the container.
+			var db *ConfiguredDB
+			err := container.Invoke(&db)
+			testutils.RequireErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestWithConfigMissingFile(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		config.Struct[DBConfig](config.JSONFile("missing.json")),
+	))
+
+	var cfg DBConfig
+	testutils.RequireErrorIs(t, container.Invoke(&cfg), os.ErrNotExist)
+}
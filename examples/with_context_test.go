@@ -0,0 +1,141 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to resolve a container with a context.
+//
+// Constructors which request a context.Context receive the context
+// passed to InvokeContext, and constructors can be given a timeout
+// with the Timeout annotation.
+
+type contextKey struct{}
+
+type Client struct {
+	Endpoint string
+}
+
+func NewClient(ctx context.Context) *Client {
+	endpoint, _ := ctx.Value(contextKey{}).(string)
+	return &Client{Endpoint: endpoint}
+}
+
+func NewSlowClient(ctx context.Context) (*Client, error) {
+	select {
+	case <-time.After(time.Second):
+		return &Client{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestInvokeContext(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(NewClient))
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "localhost")
+	var client *Client
+	testutils.RequireNoError(t, container.InvokeContext(ctx, &client))
+	testutils.RequireEquals(t, "localhost", client.Endpoint)
+}
+
+func TestWithTimeout(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(
+		NewSlowClient,
+		depinject.Timeout(10*time.Millisecond),
+	))
+
+	var client *Client
+	err := container.Invoke(&client)
+	testutils.RequireError(t, err)
+	testutils.RequireTrue(t, errors.Is(err, context.DeadlineExceeded))
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "NewSlowClient"))
+}
+
+func TestInvokeContextCancelled(t *testing.T) {
+	container := depinject.NewContainer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dependentCalled := false
+	testutils.RequireNoError(t, container.Provide(
+		// This constructor ignores its context, so its value is
+		// discarded once it returns.
+		func() *Foo {
+			cancel()
+			return &Foo{}
+		},
+		func(*Foo) *Bar {
+			dependentCalled = true
+			return &Bar{}
+		},
+	))
+
+	var bar *Bar
+	err := container.InvokeContext(ctx, &bar)
+	testutils.RequireError(t, err)
+	testutils.RequireTrue(t, errors.Is(err, context.Canceled))
+	testutils.RequireTrue(t, strings.Contains(
+		err.Error(), "TestInvokeContextCancelled.func1",
+	))
+	testutils.RequireFalse(t, dependentCalled)
+}
+
+func TestInvokeContextCancelledCleanup(t *testing.T) {
+	container := depinject.NewContainer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cleaned := false
+	testutils.RequireNoError(t, container.Provide(
+		func() (*Foo, func()) {
+			cancel()
+			return &Foo{}, func() { cleaned = true }
+		},
+	))
+
+	// The discarded value is cleaned up right away, rather than when the
+	// container is closed.
+	var foo *Foo
+	testutils.RequireError(t, container.InvokeContext(ctx, &foo))
+	testutils.RequireTrue(t, cleaned)
+}
+
+func TestWithTimeoutLateCleanup(t *testing.T) {
+	container := depinject.NewContainer()
+
+	cleaned := make(chan struct{})
+	testutils.RequireNoError(t, container.Provide(
+		func() (*Client, func()) {
+			time.Sleep(50 * time.Millisecond)
+			return &Client{}, func() { close(cleaned) }
+		},
+		depinject.Timeout(10*time.Millisecond),
+	))
+
+	var client *Client
+	err := container.Invoke(&client)
+	testutils.RequireTrue(t, errors.Is(err, context.DeadlineExceeded))
+
+	// The constructor keeps running once the timeout elapses, and its
+	// value is cleaned up when it returns.
+	select {
+	case <-cleaned:
+	case <-time.After(time.Second):
+		t.Fatal("expected the late value to be cleaned up")
+	}
+}
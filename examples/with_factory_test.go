@@ -0,0 +1,241 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to construct values on demand, rather than before their
+// dependents.
+//
+// In this case, Handler depends on a Provider[*Session], so Session is
+// only constructed once the handler calls the provider. A Worker which
+// depends on a plain func() (*Job, error) receives a factory in the
+// same way.
+
+type Session struct {
+	id int
+}
+
+type Handler struct {
+	sessions depinject.Provider[*Session]
+}
+
+func NewHandler(sessions depinject.Provider[*Session]) *Handler {
+	return &Handler{sessions: sessions}
+}
+
+type Job struct {
+	id int
+}
+
+type Worker struct {
+	jobs func() (*Job, error)
+}
+
+func NewWorker(jobs func() (*Job, error)) *Worker {
+	return &Worker{jobs: jobs}
+}
+
+func TestWithFactory(t *testing.T) {
+	container := depinject.NewContainer()
+
+	calls := 0
+	testutils.RequireNoError(t, container.Provide(
+		func() *Session {
+			calls++
+			return &Session{id: calls}
+		},
+	))
+	testutils.RequireNoError(t, container.Provide(NewHandler))
+
+	var handler *Handler
+	testutils.RequireNoError(t, container.Invoke(&handler))
+
+	// Singletons are constructed by the first call to the provider,
+	// if they have not been already, and reused afterwards.
+	session1, err := handler.sessions()
+	testutils.RequireNoError(t, err)
+	session2, err := handler.sessions()
+	testutils.RequireNoError(t, err)
+	testutils.RequireTrue(t, session1 == session2)
+	testutils.RequireEquals(t, 1, calls)
+
+	var session *Session
+	testutils.RequireNoError(t, container.Invoke(&session))
+	testutils.RequireTrue(t, session == session1)
+}
+
+func TestWithFactoryTransient(t *testing.T) {
+	container := depinject.NewContainer()
+
+	calls := 0
+	testutils.RequireNoError(t, container.Provide(
+		func() *Job {
+			calls++
+			return &Job{id: calls}
+		},
+		depinject.Transient(),
+	))
+	testutils.RequireNoError(t, container.Provide(NewWorker))
+
+	var worker *Worker
+	testutils.RequireNoError(t, container.Invoke(&worker))
+	testutils.RequireEquals(t, 0, calls)
+
+	// Transient values are constructed on every call.
+	job1, err := worker.jobs()
+	testutils.RequireNoError(t, err)
+	job2, err := worker.jobs()
+	testutils.RequireNoError(t, err)
+	testutils.RequireTrue(t, job1 != job2)
+	testutils.RequireEquals(t, 2, calls)
+}
+
+func TestWithFactoryNotForced(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// The handler is constructed before the session, and calls the
+	// provider from its constructor, which constructs the session.
+	var order []string
+	testutils.RequireNoError(t, container.Provide(
+		func(sessions depinject.Provider[*Session]) (*Handler, error) {
+			order = append(order, "handler")
+			if _, err := sessions(); err != nil {
+				return nil, err
+			}
+			return &Handler{sessions: sessions}, nil
+		},
+		func() *Session {
+			order = append(order, "session")
+			return &Session{}
+		},
+	))
+
+	var handler *Handler
+	testutils.RequireNoError(t, container.Invoke(&handler))
+	testutils.RequireEquals(t, len(order), 2)
+	testutils.RequireEquals(t, order[0], "handler")
+	testutils.RequireEquals(t, order[1], "session")
+}
+
+func TestWithFactoryError(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// Errors from constructing the value are returned by the provider.
+	errNoSession := errors.New("no session")
+	testutils.RequireNoError(t, container.Provide(
+		func() (*Session, error) { return nil, errNoSession },
+		depinject.Transient(),
+	))
+	testutils.RequireNoError(t, container.Provide(NewHandler))
+
+	var handler *Handler
+	testutils.RequireNoError(t, container.Invoke(&handler))
+
+	_, err := handler.sessions()
+	testutils.RequireErrorIs(t, err, errNoSession)
+}
+
+func TestWithFactoryCycle(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// Factories still count as dependencies when checking for cycles.
+	testutils.RequireNoError(t, container.Provide(
+		func(*Handler) *Session { return &Session{} },
+		NewHandler,
+	))
+
+	var handler *Handler
+	err := container.Invoke(&handler)
+	testutils.RequireTrue(t, errors.Is(err, graph.ErrAcyclicConstraintViolation))
+}
+
+func TestWithFactoryMissing(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(NewHandler))
+
+	var handler *Handler
+	testutils.RequireError(t, container.Invoke(&handler))
+}
+
+func TestInvokeFactory(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(
+		func() *Session { return &Session{id: 1} },
+	))
+
+	var sessions depinject.Provider[*Session]
+	testutils.RequireNoError(t, container.Invoke(&sessions))
+
+	session, err := sessions()
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, session.id, 1)
+}
+
+func TestWithFactoryFromEarlierInvoke(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(func() *Session {
+		return &Session{id: 1}
+	}))
+
+	var sessions depinject.Provider[*Session]
+	testutils.RequireNoError(t, container.Invoke(&sessions))
+
+	// The provider outlives the Invoke it was created by, and is called
+	// by a constructor while the container is resolved again.
+	testutils.RequireNoError(t, container.Provide(func() (*Job, error) {
+		session, err := sessions()
+		if err != nil {
+			return nil, err
+		}
+		return &Job{id: session.id}, nil
+	}))
+
+	done := make(chan error, 1)
+	var job *Job
+	go func() { done <- container.Invoke(&job) }()
+	select {
+	case err := <-done:
+		testutils.RequireNoError(t, err)
+		testutils.RequireEquals(t, 1, job.id)
+	case <-time.After(time.Second):
+		t.Fatal("expected the invocation not to deadlock")
+	}
+}
+
+func TestWithFactoryFromTimeoutConstructor(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		func() *Session { return &Session{id: 1} },
+		depinject.Transient(),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		NewHandler, depinject.Timeout(time.Second),
+	))
+
+	// The provider kept by a constructor with a timeout can be called
+	// once the constructor has returned.
+	testutils.RequireNoError(t, container.Provide(
+		func(handler *Handler) (*Job, error) {
+			session, err := handler.sessions()
+			if err != nil {
+				return nil, err
+			}
+			return &Job{id: session.id}, nil
+		},
+	))
+
+	var job *Job
+	testutils.RequireNoError(t, container.Invoke(&job))
+	testutils.RequireEquals(t, 1, job.id)
+}
@@ -0,0 +1,120 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how providers registered after the container
+// is resolved only re-execute the constructors they affect.
+
+func TestProvideAfterInvoke(t *testing.T) {
+	container := depinject.NewContainer()
+
+	calls := 0
+	testutils.RequireNoError(t, container.Provide(func() *Session {
+		calls++
+		return &Session{id: calls}
+	}))
+
+	var session1 *Session
+	testutils.RequireNoError(t, container.Invoke(&session1))
+
+	// New providers are resolved, while the singletons they depend on
+	// keep their values.
+	var received *Session
+	testutils.RequireNoError(t, container.Provide(func(s *Session) *Handler {
+		received = s
+		return &Handler{}
+	}))
+
+	var session2 *Session
+	var handler *Handler
+	testutils.RequireNoError(t, container.Invoke(&session2, &handler))
+	testutils.RequireTrue(t, session1 == session2)
+	testutils.RequireTrue(t, received == session1)
+	testutils.RequireEquals(t, 1, calls)
+}
+
+func TestProvideAfterInvokeChangedDependencies(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	calls := map[string]int{}
+	testutils.RequireNoError(t, container.Provide(
+		func() *Foo {
+			calls["foo"]++
+			return &Foo{}
+		},
+		func(bars []*Bar) *FooBar {
+			calls["fooBar"]++
+			return &FooBar{}
+		},
+		func(_ *Foo) *Bar {
+			calls["bar"]++
+			return &Bar{}
+		},
+	))
+
+	var fooBar1 *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar1))
+
+	// A new member of a list re-executes the constructors which depend
+	// on the list, but not the other constructors.
+	testutils.RequireNoError(t, container.Provide(NewBar))
+
+	var fooBar2 *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar2))
+	testutils.RequireEquals(t, 1, calls["foo"])
+	testutils.RequireEquals(t, 1, calls["bar"])
+	testutils.RequireEquals(t, 2, calls["fooBar"])
+}
+
+func TestProvideAfterInvokeOptional(t *testing.T) {
+	container := depinject.NewContainer()
+
+	type Params struct {
+		depinject.In
+
+		Foo *Foo `optional:"true"`
+	}
+
+	var received []*Foo
+	testutils.RequireNoError(t, container.Provide(func(p Params) *Bar {
+		received = append(received, p.Foo)
+		return &Bar{}
+	}))
+
+	var bar *Bar
+	testutils.RequireNoError(t, container.Invoke(&bar))
+
+	// An optional dependency which gains a provider re-executes its
+	// dependents.
+	testutils.RequireNoError(t, container.Provide(NewFoo))
+	testutils.RequireNoError(t, container.Invoke(&bar))
+	testutils.RequireLen(t, received, 2)
+	testutils.RequireTrue(t, received[0] == nil)
+	testutils.RequireNotNil(t, received[1])
+}
+
+func TestProvideAfterInvokeSameConstructor(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+	testutils.RequireNoError(t, container.Provide(NewBar))
+	testutils.RequireNoError(t, container.Supply(&Foo{}))
+
+	var bars []*Bar
+	testutils.RequireNoError(t, container.Invoke(&bars))
+	testutils.RequireLen(t, bars, 1)
+
+	// Providing the same constructor again keeps both of its providers
+	// ordered after their dependency.
+	testutils.RequireNoError(t, container.Provide(NewBar))
+	testutils.RequireNoError(t, container.Invoke(&bars))
+	testutils.RequireLen(t, bars, 2)
+
+	order, err := container.Order()
+	testutils.RequireNoError(t, err)
+	testutils.RequireLen(t, order, 3)
+	testutils.RequireTrue(t, order[0].Supplied)
+}
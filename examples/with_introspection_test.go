@@ -0,0 +1,113 @@
+package examples
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to inspect the providers registered in
+// a container, and the dependencies between them.
+
+func providerIDs(infos []depinject.ProviderInfo) []string {
+	ids := make([]string, len(infos))
+	for i, info := range infos {
+		ids[i] = info.ID[strings.LastIndex(info.ID, ".")+1:]
+	}
+	return ids
+}
+
+func TestIntrospection(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(NewFooBar, NewBar))
+	testutils.RequireNoError(t, container.Supply(&Foo{}))
+
+	// Providers are listed in the order they were registered.
+	providers := container.Providers()
+	testutils.RequireLen(t, providers, 3)
+	testutils.RequireEquals(t, "NewFooBar", providerIDs(providers)[0])
+	testutils.RequireEquals(
+		t, reflect.TypeOf(&FooBar{}), providers[0].Outputs[0],
+	)
+	testutils.RequireLen(t, providers[0].Dependencies, 2)
+	testutils.RequireTrue(t, strings.Contains(providers[0].Source, "types.go"))
+	testutils.RequireTrue(t, providers[2].Supplied)
+	testutils.RequireEquals(t, "", providers[2].Source)
+
+	testutils.RequireTrue(t, container.Has(reflect.TypeOf(&Bar{})))
+	testutils.RequireTrue(t, !container.Has(reflect.TypeOf(&DB{})))
+
+	dependencies, err := container.DependenciesOf(reflect.TypeOf(&FooBar{}))
+	testutils.RequireNoError(t, err)
+	testutils.RequireLen(t, dependencies, 2)
+
+	dependents, err := container.DependentsOf(reflect.TypeOf(&Foo{}))
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, []string{"NewFooBar", "NewBar"}, providerIDs(dependents))
+
+	// Every provider comes after the providers it depends on.
+	order, err := container.Order()
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, "NewFooBar", providerIDs(order)[2])
+}
+
+func TestIntrospectionFactories(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		NewHandler,
+		func() *Session { return &Session{} },
+	))
+
+	// Dependencies through factories are included.
+	dependents, err := container.DependentsOf(reflect.TypeOf(&Session{}))
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, []string{"NewHandler"}, providerIDs(dependents))
+
+	dependencies, err := container.DependenciesOf(reflect.TypeOf(&Handler{}))
+	testutils.RequireNoError(t, err)
+	testutils.RequireLen(t, dependencies, 1)
+}
+
+func TestIntrospectionMissing(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(NewBar))
+
+	_, err := container.DependenciesOf(reflect.TypeOf(&FooBar{}))
+	testutils.RequireError(t, err)
+
+	// The graph cannot be ordered while a dependency is missing.
+	_, err = container.Order()
+	testutils.RequireError(t, err)
+}
+
+func TestIntrospectionReadOnly(t *testing.T) {
+	var logs bytes.Buffer
+	container := depinject.NewContainer(
+		depinject.WithLogger(log.New(&logs, "", 0)),
+	)
+	testutils.RequireNoError(t, container.Provide(NewFooBar, NewBar))
+
+	// Queries on an invalid container return errors without logging
+	// them, and without building the container.
+	_, err := container.DependentsOf(reflect.TypeOf(&Bar{}))
+	testutils.RequireError(t, err)
+	_, err = container.Order()
+	testutils.RequireError(t, err)
+	_, err = container.Unused()
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, 0, logs.Len())
+
+	testutils.RequireNoError(t, container.Supply(&Foo{}))
+	order, err := container.Order()
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, "NewFooBar", providerIDs(order)[2])
+
+	var fooBar *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar))
+	testutils.RequireNotNil(t, fooBar)
+}
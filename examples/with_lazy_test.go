@@ -0,0 +1,146 @@
+package examples
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to break a cycle between mutually dependent constructors.
+//
+// In this case, EventBus depends on its handler, and the handler
+// publishes to the bus. Since the handler depends on a Lazy[*EventBus],
+// the bus is only resolved once the handler calls Get.
+
+type EventBus struct {
+	handler *EventHandler
+}
+
+func NewEventBus(handler *EventHandler) *EventBus {
+	return &EventBus{handler: handler}
+}
+
+type EventHandler struct {
+	bus depinject.Lazy[*EventBus]
+}
+
+func NewEventHandler(bus depinject.Lazy[*EventBus]) *EventHandler {
+	return &EventHandler{bus: bus}
+}
+
+func TestWithLazy(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(NewEventBus, NewEventHandler))
+
+	var bus *EventBus
+	testutils.RequireNoError(t, container.Invoke(&bus))
+
+	handlerBus, err := bus.handler.bus.Get()
+	testutils.RequireNoError(t, err)
+	testutils.RequireTrue(t, handlerBus == bus)
+}
+
+func TestWithLazyDeferred(t *testing.T) {
+	container := depinject.NewContainer()
+
+	calls := 0
+	testutils.RequireNoError(t, container.Provide(
+		func() *Session {
+			calls++
+			return &Session{}
+		},
+		depinject.Transient(),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		func(session depinject.Lazy[*Session]) *Handler {
+			return &Handler{sessions: depinject.Provider[*Session](session)}
+		},
+	))
+
+	// Expensive values are only constructed when they are first used.
+	var handler *Handler
+	testutils.RequireNoError(t, container.Invoke(&handler))
+	testutils.RequireEquals(t, 0, calls)
+
+	_, err := handler.sessions()
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, 1, calls)
+}
+
+func TestWithLazyCycle(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// A handler which resolves the bus from its own constructor still
+	// depends on itself, which is reported when Get is called.
+	testutils.RequireNoError(t, container.Provide(
+		NewEventBus,
+		func(bus depinject.Lazy[*EventBus]) (*EventHandler, error) {
+			if _, err := bus.Get(); err != nil {
+				return nil, err
+			}
+			return &EventHandler{bus: bus}, nil
+		},
+	))
+
+	var bus *EventBus
+	err := container.Invoke(&bus)
+	testutils.RequireTrue(t, errors.Is(err, graph.ErrAcyclicConstraintViolation))
+}
+
+type LazyA struct{}
+
+type LazyB struct{}
+
+func TestWithLazyCycleParallel(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithParallelResolution(4))
+
+	// Neither constructor depends on the other in the graph, so both are
+	// resolved at once, and each requires the other from its constructor
+	// once both have started.
+	var started sync.WaitGroup
+	started.Add(2)
+	testutils.RequireNoError(t, container.Provide(
+		func(b depinject.Provider[*LazyB]) (*LazyA, error) {
+			started.Done()
+			started.Wait()
+			if _, err := b(); err != nil {
+				return nil, err
+			}
+			return &LazyA{}, nil
+		},
+		func(a depinject.Lazy[*LazyA]) (*LazyB, error) {
+			started.Done()
+			started.Wait()
+			if _, err := a.Get(); err != nil {
+				return nil, err
+			}
+			return &LazyB{}, nil
+		},
+	))
+
+	done := make(chan error, 1)
+	var a *LazyA
+	go func() { done <- container.Invoke(&a) }()
+	select {
+	case err := <-done:
+		testutils.RequireTrue(t, errors.Is(err, graph.ErrAcyclicConstraintViolation))
+	case <-time.After(time.Second):
+		t.Fatal("expected the cycle to be reported rather than deadlock")
+	}
+}
+
+func TestWithLazyMissing(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(NewEventHandler))
+
+	var handler *EventHandler
+	testutils.RequireError(t, container.Invoke(&handler))
+}
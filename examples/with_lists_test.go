@@ -0,0 +1,133 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to invoke lists of values directly from the container
+// when list inference is enabled.
+//
+// In this case, NewBar is provided multiple times and we request
+// all the Bar instances at once, as a slice, an array, or a slice
+// of interfaces which Bar implements.
+
+func NewFooBarArray(_ *Foo, _ [3]*Bar) *FooBar {
+	return &FooBar{}
+}
+
+func TestInvokeSlice(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	testutils.RequireNoError(t, container.Supply(NewFoo()))
+	testutils.RequireNoError(t, container.Provide(NewBar, NewBar, NewBar))
+
+	var bars []*Bar
+	testutils.RequireNoError(t, container.Invoke(&bars))
+	testutils.RequireLen(t, bars, 3)
+}
+
+func TestInvokeArray(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	testutils.RequireNoError(t, container.Supply(NewFoo()))
+	testutils.RequireNoError(t, container.Provide(NewBar, NewBar, NewBar))
+
+	var bars [3]*Bar
+	testutils.RequireNoError(t, container.Invoke(&bars))
+	for _, bar := range bars {
+		testutils.RequireNotNil(t, bar)
+	}
+
+	// The array size must match the number of providers.
+	var tooManyBars [4]*Bar
+	testutils.RequireError(t, container.Invoke(&tooManyBars))
+}
+
+func TestInvokeSliceOfInterfaces(t *testing.T) {
+	container := depinject.NewContainer(
+		depinject.WithListInference(),
+		depinject.WithInterfaceInference(),
+	)
+
+	testutils.RequireNoError(t, container.Supply(NewFoo()))
+	testutils.RequireNoError(t, container.Provide(NewBar, NewBar))
+
+	var bars []BarI
+	testutils.RequireNoError(t, container.Invoke(&bars))
+	testutils.RequireLen(t, bars, 2)
+}
+
+func TestInvokeSliceWithoutListInference(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Supply(NewFoo()))
+	testutils.RequireNoError(t, container.Provide(NewBar))
+
+	// Without list inference, slices must be provided exactly as is.
+	var bars []*Bar
+	testutils.RequireError(t, container.Invoke(&bars))
+}
+
+func TestWithArrayArgument(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	testutils.RequireNoError(t, container.Supply(NewFoo()))
+	testutils.RequireNoError(t, container.Provide(
+		NewBar,
+		NewBar,
+		NewBar,
+		NewFooBarArray,
+	))
+
+	var fooBar *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar))
+	testutils.RequireNotNil(t, fooBar)
+}
+
+func NewRoutes() (*Route, *Route) {
+	return &Route{path: "/a"}, &Route{path: "/b"}
+}
+
+func TestInvokeSliceFromOneConstructor(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	// Every value of the same type returned by a constructor is
+	// contributed to the list, in the order they are returned.
+	testutils.RequireNoError(t, container.Provide(NewRoutes))
+
+	var routes []*Route
+	testutils.RequireNoError(t, container.Invoke(&routes))
+	testutils.RequireLen(t, routes, 2)
+	testutils.RequireEquals(t, routes[0].path, "/a")
+	testutils.RequireEquals(t, routes[1].path, "/b")
+
+	// A single value cannot be chosen from several.
+	var route *Route
+	testutils.RequireError(t, container.Invoke(&route))
+}
+
+func TestProvideDuplicateReturnsWithoutListInference(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireError(t, container.Provide(NewRoutes))
+}
+
+func TestInvokeSliceWithInterfaceInference(t *testing.T) {
+	container := depinject.NewContainer(
+		depinject.WithListInference(),
+		depinject.WithInterfaceInference(),
+	)
+
+	testutils.RequireNoError(t, container.Supply(NewFoo()))
+	testutils.RequireNoError(t, container.Provide(NewBar, NewBar))
+
+	// Each provider is listed once, even though its type both is the
+	// element type and is assignable to it.
+	var bars []*Bar
+	testutils.RequireNoError(t, container.Invoke(&bars))
+	testutils.RequireLen(t, bars, 2)
+}
@@ -0,0 +1,138 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to collect named values into a map
+// keyed by their names when list inference is enabled, such as for a
+// registry of plugins.
+
+type Plugin interface {
+	Run() string
+}
+
+type EchoPlugin struct{ reply string }
+
+func (p EchoPlugin) Run() string { return p.reply }
+
+type PluginName string
+
+type PluginRegistry struct {
+	Plugins map[string]Plugin
+}
+
+func NewPluginRegistry(plugins map[string]Plugin) *PluginRegistry {
+	return &PluginRegistry{Plugins: plugins}
+}
+
+func newPlugin(reply string) func() Plugin {
+	return func() Plugin { return EchoPlugin{reply: reply} }
+}
+
+func TestWithMaps(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	testutils.RequireNoError(t, container.Provide(
+		newPlugin("users"), depinject.Name("users"),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		newPlugin("orders"), depinject.Name("orders"),
+	))
+	// Unnamed providers have no key, and are left out of the map.
+	testutils.RequireNoError(t, container.Provide(newPlugin("default")))
+	testutils.RequireNoError(t, container.Provide(NewPluginRegistry))
+
+	var registry *PluginRegistry
+	testutils.RequireNoError(t, container.Invoke(&registry))
+	testutils.RequireEquals(t, 2, len(registry.Plugins))
+	testutils.RequireEquals(t, "users", registry.Plugins["users"].Run())
+	testutils.RequireEquals(t, "orders", registry.Plugins["orders"].Run())
+
+	// Maps can be invoked directly, with any key type based on string.
+	var plugins map[PluginName]Plugin
+	testutils.RequireNoError(t, container.Invoke(&plugins))
+	testutils.RequireEquals(t, 2, len(plugins))
+	testutils.RequireEquals(t, "users", plugins["users"].Run())
+}
+
+func TestWithMapsTransient(t *testing.T) {
+	for _, names := range [][]string{{"users"}, {"users", "orders"}} {
+		container := depinject.NewContainer(depinject.WithListInference())
+		for _, name := range names {
+			testutils.RequireNoError(t, container.Provide(
+				newPlugin(name), depinject.Name(name), depinject.Transient(),
+			))
+		}
+
+		// Transient values are keyed by the names of their providers too.
+		var plugins map[string]Plugin
+		testutils.RequireNoError(t, container.Invoke(&plugins))
+		testutils.RequireEquals(t, len(names), len(plugins))
+		for _, name := range names {
+			testutils.RequireEquals(t, name, plugins[name].Run())
+		}
+	}
+}
+
+func TestWithMapsOutSentinel(t *testing.T) {
+	type plugins struct {
+		depinject.Out
+		Users  Plugin `name:"users"`
+		Orders Plugin `name:"orders"`
+	}
+
+	container := depinject.NewContainer(depinject.WithListInference())
+	testutils.RequireNoError(t, container.Provide(func() plugins {
+		return plugins{
+			Users:  EchoPlugin{reply: "users"},
+			Orders: EchoPlugin{reply: "orders"},
+		}
+	}))
+
+	var registry map[string]Plugin
+	testutils.RequireNoError(t, container.Invoke(&registry))
+	testutils.RequireEquals(t, 2, len(registry))
+	testutils.RequireEquals(t, "orders", registry["orders"].Run())
+}
+
+func TestWithMapsDuplicateKeys(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	testutils.RequireNoError(t, container.Provide(
+		newPlugin("a"), depinject.Name("users"),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		newPlugin("b"), depinject.Name("users"),
+	))
+
+	var plugins map[string]Plugin
+	testutils.RequireError(t, container.Invoke(&plugins))
+}
+
+func TestWithMapsProvidedAsIs(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	// A provider of the map itself takes precedence over its values.
+	testutils.RequireNoError(t, container.Provide(
+		newPlugin("users"), depinject.Name("users"),
+	))
+	testutils.RequireNoError(t, container.Provide(func() map[string]Plugin {
+		return map[string]Plugin{"all": EchoPlugin{reply: "all"}}
+	}))
+
+	var plugins map[string]Plugin
+	testutils.RequireNoError(t, container.Invoke(&plugins))
+	testutils.RequireEquals(t, 1, len(plugins))
+	testutils.RequireEquals(t, "all", plugins["all"].Run())
+}
+
+func TestWithMapsNoProviders(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithListInference())
+
+	var plugins map[string]Plugin
+	testutils.RequireError(t, container.Invoke(&plugins))
+}
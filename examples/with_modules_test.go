@@ -0,0 +1,163 @@
+package examples
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how modules can keep their own helpers to
+// themselves, without clashing with the providers of other modules.
+
+type HTTPClient struct {
+	BaseURL string
+}
+
+type BillingService struct {
+	Client *HTTPClient
+}
+
+type SearchService struct {
+	Client *HTTPClient
+}
+
+type RetryingBillingService struct {
+	Client *HTTPClient
+}
+
+func NewBillingService(client *HTTPClient) *BillingService {
+	return &BillingService{Client: client}
+}
+
+func NewSearchService(client *HTTPClient) *SearchService {
+	return &SearchService{Client: client}
+}
+
+func NewRetryingBillingService(client *HTTPClient) *RetryingBillingService {
+	return &RetryingBillingService{Client: client}
+}
+
+func newClient(baseURL string) func() *HTTPClient {
+	return func() *HTTPClient { return &HTTPClient{BaseURL: baseURL} }
+}
+
+func TestWithModules(t *testing.T) {
+	container := depinject.NewContainer()
+
+	billing := container.Module("billing")
+	testutils.RequireNoError(t, billing.Provide(
+		newClient("billing"), depinject.Private(),
+	))
+	testutils.RequireNoError(t, billing.Provide(NewBillingService))
+
+	// The same type can be privately provided by another module.
+	search := container.Module("search")
+	testutils.RequireNoError(t, search.Provide(
+		newClient("search"), depinject.Private(),
+	))
+	testutils.RequireNoError(t, search.Provide(NewSearchService))
+
+	// Submodules see the private providers of the modules above them.
+	retries := billing.Module("retries")
+	testutils.RequireEquals(t, "billing/retries", retries.Path())
+	testutils.RequireNoError(t, retries.Provide(NewRetryingBillingService))
+
+	var (
+		billingService  *BillingService
+		searchService   *SearchService
+		retryingService *RetryingBillingService
+	)
+	testutils.RequireNoError(t, container.Invoke(
+		&billingService, &searchService, &retryingService,
+	))
+	testutils.RequireEquals(t, "billing", billingService.Client.BaseURL)
+	testutils.RequireEquals(t, "search", searchService.Client.BaseURL)
+	testutils.RequireEquals(t, "billing", retryingService.Client.BaseURL)
+}
+
+func TestWithModulesShadowing(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// A private provider takes precedence over a public provider of the
+	// same type within its module.
+	testutils.RequireNoError(t, container.Provide(newClient("shared")))
+	billing := container.Module("billing")
+	testutils.RequireNoError(t, billing.Provide(
+		newClient("billing"), depinject.Private(),
+	))
+	testutils.RequireNoError(t, billing.Provide(NewBillingService))
+	testutils.RequireNoError(t, container.Provide(NewSearchService))
+
+	var (
+		billingService *BillingService
+		searchService  *SearchService
+	)
+	testutils.RequireNoError(t, container.Invoke(
+		&billingService, &searchService,
+	))
+	testutils.RequireEquals(t, "billing", billingService.Client.BaseURL)
+	testutils.RequireEquals(t, "shared", searchService.Client.BaseURL)
+}
+
+func TestWithModulesPrivateNotVisible(t *testing.T) {
+	container := depinject.NewContainer()
+
+	billing := container.Module("billing")
+	testutils.RequireNoError(t, billing.Provide(
+		newClient("billing"), depinject.Private(),
+	))
+	testutils.RequireNoError(t, container.Module("search").Provide(
+		NewSearchService,
+	))
+
+	// The error reports that the dependency exists but is private.
+	var searchService *SearchService
+	err := container.Invoke(&searchService)
+	testutils.RequireError(t, err)
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "private"))
+
+	var client *HTTPClient
+	err = container.Invoke(&client)
+	testutils.RequireError(t, err)
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "private"))
+}
+
+func TestWithModulesConflict(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// Private providers of the same type still clash within a module.
+	billing := container.Module("billing")
+	testutils.RequireNoError(t, billing.Provide(
+		newClient("a"), depinject.Private(),
+	))
+	testutils.RequireError(t, billing.Provide(
+		newClient("b"), depinject.Private(),
+	))
+}
+
+func TestWithModulesShadowingAfterInvoke(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(newClient("root")))
+
+	billing := container.Module("billing")
+	testutils.RequireNoError(t, billing.Provide(NewBillingService))
+
+	var service *BillingService
+	testutils.RequireNoError(t, container.Invoke(&service))
+	testutils.RequireEquals(t, "root", service.Client.BaseURL)
+
+	// A private provider registered later shadows the root provider, which
+	// the service no longer depends on.
+	testutils.RequireNoError(t, billing.Provide(
+		newClient("billing"), depinject.Private(),
+	))
+	testutils.RequireNoError(t, container.Invoke(&service))
+	testutils.RequireEquals(t, "billing", service.Client.BaseURL)
+
+	dependents, err := container.DependentsOf(reflect.TypeOf(&HTTPClient{}))
+	testutils.RequireNoError(t, err)
+	testutils.RequireEmpty(t, dependents)
+}
@@ -0,0 +1,89 @@
+package examples
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how the dependency injection framework
+// handles constructors which panic.
+//
+// By default, the panic is recovered and returned as a
+// ConstructorError, which reports where the panic came from and
+// which constructors depend on it.
+
+func NewPanickingBar(_ *Foo) *Bar {
+	panic("bar exploded")
+}
+
+func TestWithPanic(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(
+		NewFoo,
+		NewPanickingBar,
+		NewFooBar,
+	))
+
+	var fooBar *FooBar
+	err := container.Invoke(&fooBar)
+	testutils.RequireError(t, err)
+
+	var constructorErr *depinject.ConstructorError
+	testutils.RequireTrue(t, errors.As(err, &constructorErr))
+	testutils.RequireEquals(t, "bar exploded", constructorErr.Value)
+	testutils.RequireTrue(t, strings.HasSuffix(constructorErr.ID, "NewPanickingBar"))
+	testutils.RequireLen(t, constructorErr.Path, 2)
+	testutils.RequireTrue(t, strings.HasSuffix(constructorErr.Path[0], "NewFooBar"))
+	testutils.RequireEquals(t, constructorErr.ID, constructorErr.Path[1])
+	testutils.RequireTrue(t, len(constructorErr.Stack) > 0)
+}
+
+type BarUser struct{}
+
+func NewBarUser(_ *Bar) *BarUser {
+	return &BarUser{}
+}
+
+func TestWithPanicPathToInvoked(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// NewBarUser also depends on the panicking constructor, but is not
+	// invoked, so it is left out of the path.
+	testutils.RequireNoError(t, container.Provide(
+		NewFoo,
+		NewPanickingBar,
+		NewBarUser,
+		NewFooBar,
+	))
+
+	var fooBar *FooBar
+	err := container.Invoke(&fooBar)
+
+	var constructorErr *depinject.ConstructorError
+	testutils.RequireTrue(t, errors.As(err, &constructorErr))
+	testutils.RequireLen(t, constructorErr.Path, 2)
+	testutils.RequireTrue(t, strings.HasSuffix(constructorErr.Path[0], "NewFooBar"))
+
+	// The error is a single line, without the stack trace.
+	testutils.RequireFalse(t, strings.Contains(constructorErr.Error(), "\n"))
+	testutils.RequireTrue(t, strings.Contains(constructorErr.Error(), "bar exploded"))
+}
+
+func TestWithPanicPassthrough(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithPanicPassthrough())
+
+	testutils.RequireNoError(t, container.Provide(NewFoo, NewPanickingBar))
+
+	defer func() {
+		testutils.RequireEquals(t, "bar exploded", recover())
+	}()
+
+	var bar *Bar
+	_ = container.Invoke(&bar)
+	t.Fatalf("expected the invocation to panic")
+}
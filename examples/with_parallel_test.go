@@ -0,0 +1,95 @@
+package examples
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to execute independent constructors concurrently.
+//
+// In this case, Dialer1 and Dialer2 do not depend on each other, so
+// they are executed at the same time. Each of them waits for the other
+// to start before returning, which would time out if they were
+// executed one after another.
+
+type Dialer1 struct{}
+
+type Dialer2 struct{}
+
+type Dialers struct{}
+
+func TestWithParallelResolution(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithParallelResolution(2))
+
+	started1, started2 := make(chan struct{}), make(chan struct{})
+	rendezvous := func(self, other chan struct{}) error {
+		close(self)
+		select {
+		case <-other:
+			return nil
+		case <-time.After(time.Second):
+			return errors.New("constructors were not executed concurrently")
+		}
+	}
+
+	testutils.RequireNoError(t, container.Provide(
+		func() (*Dialer1, error) {
+			return &Dialer1{}, rendezvous(started1, started2)
+		},
+		func() (*Dialer2, error) {
+			return &Dialer2{}, rendezvous(started2, started1)
+		},
+		func(*Dialer1, *Dialer2) *Dialers {
+			return &Dialers{}
+		},
+	))
+
+	var dialers *Dialers
+	testutils.RequireNoError(t, container.Invoke(&dialers))
+	testutils.RequireNotNil(t, dialers)
+}
+
+func TestWithParallelResolutionError(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithParallelResolution(4))
+
+	var dependentCalled atomic.Bool
+	testutils.RequireNoError(t, container.Provide(
+		func() (*Dialer1, error) {
+			return nil, errors.New("dial failed")
+		},
+		func(*Dialer1) *Dialer2 {
+			dependentCalled.Store(true)
+			return &Dialer2{}
+		},
+	))
+
+	// The failed constructor's dependents are never executed.
+	var dialer *Dialer2
+	testutils.RequireError(t, container.Invoke(&dialer))
+	testutils.RequireFalse(t, dependentCalled.Load())
+}
+
+func TestWithParallelResolutionDeterministic(t *testing.T) {
+	container := depinject.NewContainer(
+		depinject.WithParallelResolution(3),
+		depinject.WithListInference(),
+	)
+
+	// The constructors finish in the reverse order they were provided.
+	testutils.RequireNoError(t, container.Provide(
+		func() int { time.Sleep(30 * time.Millisecond); return 1 },
+		func() int { time.Sleep(20 * time.Millisecond); return 2 },
+		func() int { time.Sleep(10 * time.Millisecond); return 3 },
+	))
+
+	// Lists are still ordered by provider registration.
+	var values []int
+	testutils.RequireNoError(t, container.Invoke(&values))
+	testutils.RequireEquals(t, []int{1, 2, 3}, values)
+}
@@ -0,0 +1,77 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to set the fields of a struct which is created outside of
+// the container.
+//
+// In this case, LegacyServer is created by hand, and its fields tagged
+// with `inject` are populated from the container. The Replica field is
+// resolved from the *DB provided under the name "replica".
+
+type LegacyServer struct {
+	Primary *DB                  `inject:""`
+	Replica *DB                  `inject:"replica"`
+	Cache   *Cache               `inject:",optional"`
+	Session *Session             `inject:",optional"`
+	Jobs    func() (*Job, error) `inject:""`
+
+	// Fields without the tag are left as is.
+	Name string
+}
+
+func TestPopulate(t *testing.T) {
+	container := depinject.NewContainer()
+
+	primary, replica := &DB{}, &DB{}
+	testutils.RequireNoError(t, container.Provide(
+		func() *DB { return primary },
+	))
+	testutils.RequireNoError(t, container.Provide(
+		func() *DB { return replica },
+		depinject.Name("replica"),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		func() *Session { return &Session{} },
+		func() *Job { return &Job{} },
+	))
+
+	server := &LegacyServer{Name: "legacy"}
+	testutils.RequireNoError(t, container.Populate(server))
+	testutils.RequireTrue(t, server.Primary == primary)
+	testutils.RequireTrue(t, server.Replica == replica)
+	testutils.RequireNotNil(t, server.Session)
+	testutils.RequireTrue(t, server.Cache == nil)
+	testutils.RequireEquals(t, server.Name, "legacy")
+
+	job, err := server.Jobs()
+	testutils.RequireNoError(t, err)
+	testutils.RequireNotNil(t, job)
+}
+
+func TestPopulateMissing(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// Named providers are only injected into fields which request them
+	// by name, so the unnamed Primary field has no provider.
+	testutils.RequireNoError(t, container.Provide(
+		func() *DB { return &DB{} },
+		depinject.Name("replica"),
+	))
+
+	server := &LegacyServer{}
+	testutils.RequireError(t, container.Populate(server))
+}
+
+func TestPopulateInvalidTarget(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireError(t, container.Populate(LegacyServer{}))
+	testutils.RequireError(t, container.Populate((*LegacyServer)(nil)))
+}
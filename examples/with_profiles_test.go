@@ -0,0 +1,95 @@
+package examples
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to provide different implementations
+// of a type depending on the environment, either with a condition or
+// with profiles.
+
+type Mailer interface {
+	Send(to string) string
+}
+
+type SMTPMailer struct{}
+
+func (SMTPMailer) Send(to string) string { return "smtp:" + to }
+
+type FakeMailer struct{}
+
+func (FakeMailer) Send(to string) string { return "fake:" + to }
+
+func NewSMTPMailer() Mailer { return SMTPMailer{} }
+
+func NewFakeMailer() Mailer { return FakeMailer{} }
+
+func TestWithProfiles(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithProfiles("prod"))
+
+	testutils.RequireNoError(t, container.Provide(
+		NewSMTPMailer, depinject.Profile("prod"),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		NewFakeMailer, depinject.Profile("dev", "test"),
+	))
+
+	// Only the provider of the active profile is registered.
+	var mailer Mailer
+	testutils.RequireNoError(t, container.Invoke(&mailer))
+	testutils.RequireEquals(t, "smtp:a", mailer.Send("a"))
+}
+
+func TestWithProfilesNoneActive(t *testing.T) {
+	var logs bytes.Buffer
+	container := depinject.NewContainer(
+		depinject.WithLogger(log.New(&logs, "", 0)),
+	)
+
+	testutils.RequireNoError(t, container.Provide(
+		NewSMTPMailer, depinject.Profile("prod"),
+	))
+
+	// Inactive providers are listed when the container reports errors.
+	var mailer Mailer
+	testutils.RequireError(t, container.Invoke(&mailer))
+	testutils.RequireTrue(t, strings.Contains(logs.String(), "inactive:"))
+	testutils.RequireTrue(t, strings.Contains(logs.String(), "[profiles=prod]"))
+}
+
+func TestProvideIf(t *testing.T) {
+	for _, production := range []bool{true, false} {
+		container := depinject.NewContainer()
+
+		isProduction := func() bool { return production }
+		testutils.RequireNoError(t, container.ProvideIf(
+			isProduction, NewSMTPMailer,
+		))
+		testutils.RequireNoError(t, container.ProvideIf(
+			func() bool { return !isProduction() }, NewFakeMailer,
+		))
+
+		var mailer Mailer
+		testutils.RequireNoError(t, container.Invoke(&mailer))
+		if production {
+			testutils.RequireEquals(t, "smtp:a", mailer.Send("a"))
+		} else {
+			testutils.RequireEquals(t, "fake:a", mailer.Send("a"))
+		}
+	}
+}
+
+func TestProvideIfInvalidConstructor(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// Inactive constructors are still checked.
+	testutils.RequireError(t, container.ProvideIf(
+		func() bool { return false }, 42,
+	))
+}
@@ -0,0 +1,44 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to seal a container, so that no more
+// providers can be registered once its values are in use.
+
+func TestSeal(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(NewFoo, NewBar))
+
+	container.Seal()
+	testutils.RequireTrue(t, container.Sealed())
+	testutils.RequireErrorIs(
+		t, container.Provide(NewFooBar), depinject.ErrContainerSealed,
+	)
+	testutils.RequireErrorIs(
+		t, container.Supply(&DB{}), depinject.ErrContainerSealed,
+	)
+
+	// The providers registered before sealing are still invokable.
+	var bar *Bar
+	testutils.RequireNoError(t, container.Invoke(&bar))
+	testutils.RequireNotNil(t, bar)
+}
+
+func TestWithAutoSeal(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithAutoSeal())
+	testutils.RequireNoError(t, container.Provide(NewFoo, NewBar))
+	testutils.RequireTrue(t, !container.Sealed())
+
+	// The container is sealed once it is resolved.
+	var bar *Bar
+	testutils.RequireNoError(t, container.Invoke(&bar))
+	testutils.RequireTrue(t, container.Sealed())
+	testutils.RequireErrorIs(
+		t, container.Provide(NewFooBar), depinject.ErrContainerSealed,
+	)
+}
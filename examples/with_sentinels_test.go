@@ -1,6 +1,9 @@
 package examples
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/skjdfhkskjds/depinject"
@@ -62,6 +65,60 @@ func TestWithInSentinelsMultiple(t *testing.T) {
 	testutils.RunMultiWithoutSTDOUT(t, TestWithInSentinels, 100)
 }
 
+type BarUserWithIn struct{}
+
+func NewBarUserWithIn(in FooBarWithIn) *BarUserWithIn {
+	_ = NewFooBar(in.Foo, in.Bar)
+	return &BarUserWithIn{}
+}
+
+func TestWithInSentinelsShared(t *testing.T) {
+	for _, inferLists := range []bool{false, true} {
+		container := depinject.NewContainer()
+		if inferLists {
+			container = depinject.NewContainer(depinject.WithListInference())
+		}
+
+		// Both constructors take the same In struct, which is only
+		// constructed once.
+		testutils.RequireNoError(t, container.Provide(
+			NewFoo, NewBar, NewFooBarWithIn, NewBarUserWithIn,
+		))
+
+		var (
+			fooBar  *FooBar
+			barUser *BarUserWithIn
+		)
+		testutils.RequireNoError(t, container.Invoke(&fooBar, &barUser))
+	}
+}
+
+func TestWithInSentinelsSharedErrors(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Supply(&Bar{}))
+	testutils.RequireNoError(t, container.Provide(
+		func() (*Foo, error) { return nil, errNoFoo },
+		depinject.Transient(),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		NewFooBarWithIn, NewBarUserWithIn,
+	))
+
+	// The shared node is reported on as is, rather than on the first
+	// constructor which took the struct.
+	for _, provider := range container.Providers() {
+		if provider.Outputs[0] == reflect.TypeOf(FooBarWithIn{}) {
+			testutils.RequireEquals(t, "", provider.Source)
+		}
+	}
+
+	var barUser *BarUserWithIn
+	err := container.Invoke(&barUser)
+	testutils.RequireErrorIs(t, err, errNoFoo)
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "FooBarWithIn"))
+	testutils.RequireTrue(t, !strings.Contains(err.Error(), "NewFooBarWithIn"))
+}
+
 func TestWithOutSentinels(t *testing.T) {
 	container := depinject.NewContainer(
 		depinject.WithOutSentinel(),
@@ -104,3 +161,230 @@ func TestWithInAndOutSentinels(t *testing.T) {
 	testutils.RequireNotNil(t, fooBar)
 	fooBar.Print()
 }
+
+// Sentinel struct fields of the same type are told apart by name or
+// group tags.
+
+type Route struct {
+	path string
+}
+
+type RoutesWithOut struct {
+	depinject.Out
+
+	Admin  *DB    `name:"admin"`
+	Users  *DB    `name:"users"`
+	Health *Route `group:"routes"`
+	Status *Route `group:"routes"`
+}
+
+type RoutesWithIn struct {
+	depinject.In
+
+	Admin   *DB      `name:"admin"`
+	Users   *DB      `name:"users"`
+	Metrics *DB      `name:"metrics" optional:"true"`
+	Routes  []*Route `group:"routes"`
+}
+
+func TestWithSentinelsDuplicateTypes(t *testing.T) {
+	container := depinject.NewContainer(
+		depinject.WithInSentinel(),
+		depinject.WithOutSentinel(),
+	)
+
+	admin, users := &DB{}, &DB{}
+	testutils.RequireNoError(t, container.Provide(
+		func() RoutesWithOut {
+			return RoutesWithOut{
+				Admin:  admin,
+				Users:  users,
+				Health: &Route{path: "/health"},
+				Status: &Route{path: "/status"},
+			}
+		},
+	))
+
+	var in RoutesWithIn
+	testutils.RequireNoError(t, container.Provide(
+		func(params RoutesWithIn) *FooBar {
+			in = params
+			return &FooBar{}
+		},
+	))
+
+	var fooBar *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar))
+	testutils.RequireTrue(t, in.Admin == admin)
+	testutils.RequireTrue(t, in.Users == users)
+	testutils.RequireTrue(t, in.Metrics == nil)
+	testutils.RequireLen(t, in.Routes, 2)
+}
+
+func TestWithSentinelsIndistinguishable(t *testing.T) {
+	type Params struct {
+		depinject.In
+
+		First  *DB
+		Second *DB
+	}
+
+	type Results struct {
+		depinject.Out
+
+		First  *DB
+		Second *DB
+	}
+
+	// Fields of the same type without tags are rejected.
+	container := depinject.NewContainer(depinject.WithInSentinel())
+	testutils.RequireError(t, container.Provide(
+		func(Params) *FooBar { return &FooBar{} },
+	))
+
+	container = depinject.NewContainer(depinject.WithOutSentinel())
+	testutils.RequireError(t, container.Provide(
+		func() Results { return Results{} },
+	))
+}
+
+// Sentinel structs can be nested in other sentinel structs, in which
+// case their fields are flattened into the outer struct.
+
+type CommonDeps struct {
+	depinject.In
+
+	Foo *Foo
+}
+
+type FooBarWithNestedIn struct {
+	CommonDeps
+
+	Bar *Bar
+}
+
+type CommonResults struct {
+	depinject.Out
+
+	Foo *Foo
+}
+
+type FooBarWithNestedOut struct {
+	depinject.Out
+
+	Common CommonResults
+	Bar    *Bar
+}
+
+func TestWithNestedSentinels(t *testing.T) {
+	container := depinject.NewContainer(
+		depinject.WithInSentinel(),
+		depinject.WithOutSentinel(),
+	)
+
+	foo, bar := &Foo{}, &Bar{}
+	var in FooBarWithNestedIn
+	testutils.RequireNoError(t, container.Provide(
+		func() FooBarWithNestedOut {
+			return FooBarWithNestedOut{
+				Common: CommonResults{Foo: foo},
+				Bar:    bar,
+			}
+		},
+		func(params FooBarWithNestedIn) *FooBar {
+			in = params
+			return &FooBar{}
+		},
+	))
+
+	var fooBar *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar))
+	testutils.RequireNotNil(t, fooBar)
+	testutils.RequireTrue(t, in.Foo == foo)
+	testutils.RequireTrue(t, in.Bar == bar)
+}
+
+// Sentinel structs are parsed without any options, and can be passed to
+// and returned from constructors as pointers.
+
+func TestWithPointerSentinels(t *testing.T) {
+	container := depinject.NewContainer()
+
+	foo, bar := &Foo{}, &Bar{}
+	var in *FooBarWithIn
+	testutils.RequireNoError(t, container.Provide(
+		func() *FooBarWithOut {
+			return &FooBarWithOut{Foo: foo, Bar: bar}
+		},
+		func(params *FooBarWithIn) *FooBar {
+			in = params
+			return &FooBar{}
+		},
+	))
+
+	var fooBar *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar))
+	testutils.RequireNotNil(t, fooBar)
+	testutils.RequireNotNil(t, in)
+	testutils.RequireTrue(t, in.Foo == foo)
+	testutils.RequireTrue(t, in.Bar == bar)
+}
+
+func TestWithoutSentinels(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithoutSentinels())
+
+	// The struct is provided as is, rather than its fields.
+	testutils.RequireNoError(t, container.Provide(NewFooBarWithOut))
+	testutils.RequireNoError(t, container.Provide(NewFooBarWithIn))
+
+	var fooBar *FooBar
+	testutils.RequireError(t, container.Invoke(&fooBar))
+}
+
+// Constructors which return an Out sentinel struct can also return an
+// error, in which case none of the struct's fields are provided.
+
+type DBWithOut struct {
+	depinject.Out
+
+	DB *DB
+}
+
+var (
+	errNoDB  = errors.New("no database")
+	errNoFoo = errors.New("no foo")
+)
+
+func NewDBWithOut() (DBWithOut, error) {
+	return DBWithOut{DB: &DB{}}, errNoDB
+}
+
+func TestWithOutSentinelsError(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(NewDBWithOut))
+
+	// The error is reported on the constructor, rather than on the
+	// provider generated for the field.
+	var db *DB
+	err := container.Invoke(&db)
+	testutils.RequireErrorIs(t, err, errNoDB)
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "NewDBWithOut"))
+	testutils.RequireTrue(t, !strings.Contains(err.Error(), "DBWithOut.DB"))
+	testutils.RequireTrue(t, db == nil)
+}
+
+func TestWithOutSentinelsErrorOnDemand(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		NewDBWithOut, depinject.Transient(),
+	))
+
+	var dbs depinject.Provider[*DB]
+	testutils.RequireNoError(t, container.Invoke(&dbs))
+
+	db, err := dbs()
+	testutils.RequireErrorIs(t, err, errNoDB)
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "NewDBWithOut"))
+	testutils.RequireTrue(t, !strings.Contains(err.Error(), "DBWithOut.DB"))
+	testutils.RequireTrue(t, db == nil)
+}
@@ -0,0 +1,101 @@
+package examples
+
+import (
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to construct plain structs without writing a constructor.
+//
+// In this case, Services is provided with depinject.Struct, so its
+// exported fields are resolved from the container. The Pool field is
+// resolved from the provider named "main", the Cache field is optional,
+// and the Label field is skipped.
+
+type Services struct {
+	DB    *DB
+	Pool  *Pool  `inject:"main"`
+	Cache *Cache `inject:",optional"`
+	Label string `inject:"-"`
+
+	// Unexported fields are left as is.
+	calls int
+}
+
+func TestWithStruct(t *testing.T) {
+	container := depinject.NewContainer()
+
+	db, pool := &DB{}, &Pool{}
+	testutils.RequireNoError(t, container.Supply(db))
+	testutils.RequireNoError(t, container.Provide(
+		func() *Pool { return pool },
+		depinject.Name("main"),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		depinject.Struct[*Services](),
+		depinject.Struct[Services](),
+	))
+
+	var services *Services
+	var servicesValue Services
+	testutils.RequireNoError(t, container.Invoke(&services, &servicesValue))
+	testutils.RequireTrue(t, services.DB == db)
+	testutils.RequireTrue(t, services.Pool == pool)
+	testutils.RequireTrue(t, services.Cache == nil)
+	testutils.RequireEquals(t, services.Label, "")
+	testutils.RequireEquals(t, services.calls, 0)
+	testutils.RequireTrue(t, servicesValue.DB == db)
+}
+
+func TestWithStructMissing(t *testing.T) {
+	container := depinject.NewContainer()
+
+	// Fields which are not optional must be provided.
+	testutils.RequireNoError(t, container.Provide(depinject.Struct[*Services]()))
+
+	var services *Services
+	testutils.RequireError(t, container.Invoke(&services))
+}
+
+func TestWithStructInvalid(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireError(t, container.Provide(depinject.Struct[int]()))
+}
+
+func TestWithStructDuplicateFieldTypes(t *testing.T) {
+	container := depinject.NewContainer()
+
+	type Replicas struct {
+		Primary *DB
+		Replica *DB `inject:"replica"`
+	}
+
+	// Fields of the same type are told apart by name.
+	primary, replica := &DB{}, &DB{}
+	testutils.RequireNoError(t, container.Supply(primary))
+	testutils.RequireNoError(t, container.Provide(
+		func() *DB { return replica },
+		depinject.Name("replica"),
+	))
+	testutils.RequireNoError(t, container.Provide(depinject.Struct[*Replicas]()))
+
+	var replicas *Replicas
+	testutils.RequireNoError(t, container.Invoke(&replicas))
+	testutils.RequireTrue(t, replicas.Primary == primary)
+	testutils.RequireTrue(t, replicas.Replica == replica)
+}
+
+func TestWithStructIndistinguishableFields(t *testing.T) {
+	container := depinject.NewContainer()
+
+	type Replicas struct {
+		Primary *DB
+		Replica *DB
+	}
+
+	testutils.RequireError(t, container.Provide(depinject.Struct[*Replicas]()))
+}
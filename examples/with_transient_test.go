@@ -0,0 +1,81 @@
+package examples
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to use the dependency injection
+// framework to provide values which are constructed for every
+// dependent, rather than once for the lifetime of the container.
+//
+// In this case, Buffer is provided with the Transient annotation, so
+// Reader, Writer and every call to Invoke receive their own Buffer.
+
+type Buffer struct {
+	id int
+}
+
+type Reader struct {
+	buf *Buffer
+}
+
+func NewReader(buf *Buffer) *Reader {
+	return &Reader{buf: buf}
+}
+
+type Writer struct {
+	buf *Buffer
+}
+
+func NewWriter(buf *Buffer) *Writer {
+	return &Writer{buf: buf}
+}
+
+func TestWithTransient(t *testing.T) {
+	container := depinject.NewContainer()
+
+	calls := 0
+	testutils.RequireNoError(t, container.Provide(
+		func() *Buffer {
+			calls++
+			return &Buffer{id: calls}
+		},
+		depinject.Transient(),
+	))
+	testutils.RequireNoError(t, container.Provide(NewReader, NewWriter))
+
+	var reader *Reader
+	var writer *Writer
+	testutils.RequireNoError(t, container.Invoke(&reader, &writer))
+	testutils.RequireEquals(t, 2, calls)
+	testutils.RequireTrue(t, reader.buf != writer.buf)
+
+	// Every invocation constructs a new buffer, while singletons are
+	// only constructed once.
+	var buf1, buf2 *Buffer
+	var reader2 *Reader
+	testutils.RequireNoError(t, container.Invoke(&buf1, &buf2, &reader2))
+	testutils.RequireEquals(t, 4, calls)
+	testutils.RequireTrue(t, buf1 != buf2)
+	testutils.RequireTrue(t, reader == reader2)
+}
+
+func TestWithTransientCycle(t *testing.T) {
+	container := depinject.NewContainer()
+
+	testutils.RequireNoError(t, container.Provide(
+		func(*Writer) *Buffer { return &Buffer{} },
+		depinject.Transient(),
+	))
+	testutils.RequireNoError(t, container.Provide(NewWriter))
+
+	// Transient constructors are still checked for cycles.
+	var writer *Writer
+	err := container.Invoke(&writer)
+	testutils.RequireTrue(t, errors.Is(err, graph.ErrAcyclicConstraintViolation))
+}
@@ -0,0 +1,107 @@
+package examples
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skjdfhkskjds/depinject"
+	"github.com/skjdfhkskjds/depinject/internal/testutils"
+)
+
+// This example demonstrates how to find the providers which are not
+// used by any of the types invoked from the container.
+
+func TestUnused(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		NewFoo, NewBar, NewFooBar, func() *DB { return &DB{} },
+	))
+
+	// Providers are used if a root depends on them, directly or not.
+	unused, err := container.Unused(reflect.TypeOf(&Bar{}))
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, []string{"NewFooBar", "func1"}, providerIDs(unused))
+
+	// Invoked types are roots as well.
+	var fooBar *FooBar
+	testutils.RequireNoError(t, container.Invoke(&fooBar))
+	unused, err = container.Unused()
+	testutils.RequireNoError(t, err)
+	testutils.RequireEquals(t, []string{"func1"}, providerIDs(unused))
+}
+
+func TestUnusedThroughFactories(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		NewHandler,
+		NewEventHandler,
+		NewEventBus,
+		func() *Session { return &Session{} },
+	))
+
+	// The providers behind factories and lazy dependencies are used,
+	// even if they have not been called.
+	unused, err := container.Unused(
+		reflect.TypeOf(&Handler{}), reflect.TypeOf(&EventHandler{}),
+	)
+	testutils.RequireNoError(t, err)
+	testutils.RequireEmpty(t, unused)
+}
+
+func TestWithStrictUnused(t *testing.T) {
+	container := depinject.NewContainer(
+		depinject.WithStrictUnused(reflect.TypeOf(&Bar{})),
+	)
+	testutils.RequireNoError(t, container.Provide(
+		NewFoo, NewBar, func() *DB { return &DB{} },
+	))
+
+	var bar *Bar
+	err := container.Invoke(&bar)
+	testutils.RequireErrorIs(t, err, depinject.ErrUnusedProviders)
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "func1"))
+
+	// Invoking the unused provider does not make it used, since only
+	// the given roots are checked.
+	var db *DB
+	err = container.Invoke(&bar, &db)
+	testutils.RequireErrorIs(t, err, depinject.ErrUnusedProviders)
+}
+
+func TestWithStrictUnusedRoots(t *testing.T) {
+	container := depinject.NewContainer(depinject.WithStrictUnused(
+		reflect.TypeOf(&Bar{}), reflect.TypeOf(&DB{}),
+	))
+	testutils.RequireNoError(t, container.Provide(
+		NewFoo, NewBar, func() *DB { return &DB{} },
+	))
+
+	// The container builds regardless of which of the roots are invoked
+	// first.
+	var bar *Bar
+	testutils.RequireNoError(t, container.Invoke(&bar))
+	testutils.RequireNotNil(t, bar)
+}
+
+func TestCheckUnused(t *testing.T) {
+	container := depinject.NewContainer()
+	testutils.RequireNoError(t, container.Provide(
+		NewFoo, NewBar, func() *DB { return &DB{} },
+	))
+
+	err := container.CheckUnused(reflect.TypeOf(&Bar{}))
+	testutils.RequireErrorIs(t, err, depinject.ErrUnusedProviders)
+	testutils.RequireTrue(t, strings.Contains(err.Error(), "func1"))
+	testutils.RequireTrue(t, !strings.Contains(err.Error(), "NewBar"))
+
+	// The types invoked from the container are not taken into account.
+	var db *DB
+	testutils.RequireNoError(t, container.Invoke(&db))
+	err = container.CheckUnused(reflect.TypeOf(&Bar{}))
+	testutils.RequireErrorIs(t, err, depinject.ErrUnusedProviders)
+
+	testutils.RequireNoError(t, container.CheckUnused(
+		reflect.TypeOf(&Bar{}), reflect.TypeOf(&DB{}),
+	))
+}
@@ -0,0 +1,77 @@
+package depinject
+
+import (
+	"time"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+)
+
+// An Annotation configures how the container treats the constructors
+// it is provided alongside. Annotations can be passed to Provide in any
+// position, and apply to every constructor in that call.
+//
+//	container.Provide(NewDB, NewCache, Timeout(5*time.Second))
+type Annotation func(*types.Node)
+
+// Bounds the time the constructor is allowed to run for. A
+// context.Context requested by the constructor is cancelled once
+// the timeout elapses. A constructor which ignores its context keeps
+// running in the background, and once it returns, the cleanups of its
+// values are run, since they are never injected.
+func Timeout(timeout time.Duration) Annotation {
+	return func(n *types.Node) {
+		n.SetTimeout(timeout)
+	}
+}
+
+// Provides the constructor's values under the given name. Named values
+// are only injected into fields which request them by name, and the
+// same type can be provided under several names.
+func Name(name string) Annotation {
+	return func(n *types.Node) {
+		n.SetName(name)
+	}
+}
+
+// Only provides the constructor if one of the given profiles is active
+// in the container, as set with WithProfiles. Constructors without
+// profiles are provided regardless of the container's profiles.
+func Profile(profiles ...string) Annotation {
+	return func(n *types.Node) {
+		n.AddProfiles(profiles...)
+	}
+}
+
+// Only makes the constructor's values visible to the constructors of the
+// module it is provided in, and of its submodules. Private values do not
+// clash with the values of the same type provided outside of the module.
+// Constructors provided outside of any module are never private.
+func Private() Annotation {
+	return func(n *types.Node) {
+		n.SetPrivate(true)
+	}
+}
+
+// Instructs the container to execute the constructor for every value
+// it needs to inject, rather than once for the lifetime of the container.
+// Each dependent, and each call to Invoke, receives a new value.
+func Transient() Annotation {
+	return func(n *types.Node) {
+		n.SetTransient(true)
+	}
+}
+
+// splitAnnotations separates the annotations from the constructors in
+// the given list of values, preserving the order of the constructors.
+func splitAnnotations(values []any) ([]any, []Annotation) {
+	constructors := make([]any, 0, len(values))
+	annotations := make([]Annotation, 0)
+	for _, value := range values {
+		if annotation, ok := value.(Annotation); ok {
+			annotations = append(annotations, annotation)
+			continue
+		}
+		constructors = append(constructors, value)
+	}
+	return constructors, annotations
+}
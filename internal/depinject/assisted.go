@@ -0,0 +1,158 @@
+package depinject
+
+import (
+	"context"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+)
+
+// assistedFactoryOf returns the function type of the given type if it
+// is a depinject.Factory[F].
+func assistedFactoryOf(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !isGenericType(t, "Factory") {
+		return nil, false
+	}
+	return t.Field(0).Type, true
+}
+
+// assistedProviderOf returns the provider of the value returned by the
+// given assisted factory function, and the index of the provider's
+// dependency which each of the function's parameters is passed as.
+// Parameters are matched in order, to the first dependency of the same
+// type which has not been matched yet.
+func (c *Container) assistedProviderOf(
+	fnType reflect.Type,
+) (*types.Node, []int, error) {
+	if !reflect.IsFunc(fnType) || fnType.NumOut() != 2 ||
+		fnType.Out(1) != reflect.ErrorType {
+		return nil, nil, errors.Newf(invalidAssistedFactoryErrMsg, fnType)
+	}
+
+	providers, err := c.registry.Lookup(fnType.Out(0), false)
+	if err != nil {
+		return nil, nil, err
+	} else if len(providers) != 1 {
+		return nil, nil, errors.Newf(expected1ProviderErrMsg, len(providers))
+	}
+
+	provider := providers[0]
+	dependencies := provider.Dependencies()
+	matched := make([]int, fnType.NumIn())
+	used := make(map[int]bool, len(dependencies))
+	for i := range matched {
+		matched[i] = -1
+		for j, dep := range dependencies {
+			if !used[j] && dep.Type == fnType.In(i) {
+				matched[i], used[j] = j, true
+				break
+			}
+		}
+		if matched[i] < 0 {
+			return nil, nil, errors.Newf(
+				unmatchedAssistedParamErrMsg, fnType.In(i), provider.ID(),
+			)
+		}
+	}
+	return provider, matched, nil
+}
+
+// assistedProviders returns the providers which are constructed by
+// assisted factories, along with the indices of the dependencies they
+// receive from the factories' parameters rather than from the registry.
+func (c *Container) assistedProviders() (map[*types.Node]map[int]struct{}, error) {
+	assisted := make(map[*types.Node]map[int]struct{})
+	for _, node := range c.graph.Vertices() {
+		for _, dep := range node.Dependencies() {
+			fnType, ok := assistedFactoryOf(dep.Type)
+			if !ok {
+				continue
+			}
+
+			provider, matched, err := c.assistedProviderOf(fnType)
+			if err != nil {
+				return nil, newContainerError(err, buildErrorName, node.ID())
+			}
+
+			if assisted[provider] == nil {
+				assisted[provider] = make(map[int]struct{})
+			}
+			for _, j := range matched {
+				assisted[provider][j] = struct{}{}
+			}
+		}
+	}
+	return assisted, nil
+}
+
+// isAssisted returns true if the given node is only constructed by
+// assisted factories.
+func (c *Container) isAssisted(node *types.Node) bool {
+	_, ok := c.assisted[node]
+	return ok
+}
+
+// isAssistedArg returns true if the dependency of the given node at the
+// given index is passed to it by an assisted factory.
+func (c *Container) isAssistedArg(node *types.Node, i int) bool {
+	_, ok := c.assisted[node][i]
+	return ok
+}
+
+// newAssistedFactory returns a depinject.Factory[F] of the given type,
+// whose function constructs a new value from its parameters and the
+// remaining dependencies of the value's provider.
+func (c *Container) newAssistedFactory(
+	ctx context.Context, factoryType, fnType reflect.Type,
+) reflect.Value {
+	factory := reflect.New(factoryType).Elem()
+	factory.Field(0).Set(reflect.MakeFunc(fnType, func(in []reflect.Value) []reflect.Value {
+		out := reflect.New(fnType.Out(0)).Elem()
+		errOut := reflect.New(reflect.ErrorType).Elem()
+
+		value, err := c.callAssistedFactory(ctx, fnType, in)
+		if err != nil {
+			errOut.Set(reflect.ValueOf(err))
+		} else {
+			out.Set(value)
+		}
+		return []reflect.Value{out, errOut}
+	}))
+	return factory
+}
+
+// callAssistedFactory constructs a new value with the given parameters
+// of an assisted factory function.
+func (c *Container) callAssistedFactory(
+	ctx context.Context, fnType reflect.Type, in []reflect.Value,
+) (reflect.Value, error) {
+	ctx, release := c.joinSession(ctx)
+	defer release()
+
+	value, err := func() (reflect.Value, error) {
+		provider, matched, err := c.assistedProviderOf(fnType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		args := make(map[int]reflect.Value, len(in))
+		for i, j := range matched {
+			args[j] = in[i]
+		}
+
+		instance, err := c.construct(ctx, provider, args)
+		if err != nil {
+			return reflect.Value{}, newContainerError(
+				err, resolveErrorName, provider.ID(),
+			)
+		}
+		return instance.ValueOf(fnType.Out(0), false, c.inferInterfaces)
+	}()
+	if err != nil {
+		return reflect.Value{}, newContainerError(
+			err, factoryErrorName, fnType.String(),
+		)
+	}
+	return value, nil
+}
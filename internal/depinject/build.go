@@ -3,45 +3,135 @@ package depinject
 import (
 	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
 	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
 	"github.com/skjdfhkskjds/depinject/internal/reflect"
 )
 
 const buildErrorName = "build"
 
 func (c *Container) build() error {
+	// find the providers constructed by assisted factories first, since
+	// the arguments the factories pass to them have no providers
+	assisted, err := c.assistedProviders()
+	if err != nil {
+		return err
+	}
+	c.assisted = assisted
+
+	// edges from a previous build are kept, and adding them again has no
+	// effect, so only the edges of new nodes, and of nodes whose
+	// dependencies have different providers, are added below
+	c.removeStaleEdges(assisted)
+	if err = c.buildEdges(c.graph, assisted); err != nil {
+		return err
+	}
+
+	nodes, err := c.graph.TopologicalSort()
+	if err != nil {
+		return err
+	}
+	c.sortedNodes = nodes
+	return nil
+}
+
+// buildEdges adds the edges between the nodes of the given graph, given
+// the providers constructed by assisted factories.
+func (c *Container) buildEdges(
+	g *graph.DAG[*types.Node], assisted map[*types.Node]map[int]struct{},
+) error {
 	// iterate through every node in the graph and create incoming
 	// edges for each node's dependencies
-	for _, node := range c.graph.Vertices() {
-		for _, dep := range node.Dependencies() {
-			if err := c.buildDependencyForNode(node, dep); err != nil {
+	for _, node := range g.Vertices() {
+		for i, dep := range node.Dependencies() {
+			if _, ok := c.producedBy(dep.Type); ok {
+				continue
+			} else if _, ok = assisted[node][i]; ok {
+				continue
+			}
+			if err := c.buildDependencyForNode(g, node, dep); err != nil {
 				return newContainerError(err, buildErrorName, node.ID())
 			}
 		}
 	}
 
-	nodes, err := c.graph.TopologicalSort()
-	if err != nil {
-		return err
+	// factory dependencies are built once every hard edge is in place,
+	// so that cycles through a factory are detected regardless of the
+	// order in which the nodes were registered
+	for _, node := range g.Vertices() {
+		for _, dep := range node.Dependencies() {
+			t, ok := c.producedBy(dep.Type)
+			if !ok {
+				continue
+			}
+			err := c.buildFactoryDependencyForNode(g, node, t, isLazy(dep.Type))
+			if err != nil {
+				return newContainerError(err, buildErrorName, node.ID())
+			}
+		}
 	}
-	c.sortedNodes = nodes
 	return nil
 }
 
+// removeStaleEdges removes the incoming edges of the nodes which have an
+// edge from a node that no longer provides any of their dependencies,
+// such as a provider shadowed by a private provider since the last
+// build, so that their edges are added again from the current providers.
+func (c *Container) removeStaleEdges(assisted map[*types.Node]map[int]struct{}) {
+	for _, node := range c.graph.Vertices() {
+		sources := c.edgeSourcesOf(node, assisted)
+		for _, predecessor := range c.graph.Predecessors(node) {
+			if !sources[predecessor.ID()] {
+				c.graph.RemoveEdgesTo(node)
+				break
+			}
+		}
+	}
+}
+
+// edgeSourcesOf returns the IDs of the nodes which the given node has an
+// edge from, following the same rules as buildEdges.
+func (c *Container) edgeSourcesOf(
+	node *types.Node, assisted map[*types.Node]map[int]struct{},
+) map[string]bool {
+	sources := make(map[string]bool)
+	for i, dep := range node.Dependencies() {
+		var providers []*types.Node
+		if t, ok := c.producedBy(dep.Type); ok {
+			if !isLazy(dep.Type) {
+				providers, _ = c.registry.LookupNamed(t, "", node.Module(), true)
+			}
+		} else if _, ok = assisted[node][i]; !ok && !reflect.IsContext(dep.Type) {
+			providers, _ = c.providersOfDep(dep)
+		}
+		for _, provider := range providers {
+			sources[provider.ID()] = true
+		}
+	}
+	return sources
+}
+
 // buildDependencyForNode creates edges from all providers of a particular
 // node's dependency.
 func (c *Container) buildDependencyForNode(
+	g *graph.DAG[*types.Node],
 	node *types.Node,
 	dep *reflect.Arg,
 ) error {
+	// Contexts are supplied at resolution time, not by a provider.
+	if reflect.IsContext(dep.Type) {
+		return nil
+	}
+
 	// Search the registry for the dependency
-	providers, err := c.registry.Lookup(dep.Type, dep.IsVariadic)
+	providers, err := c.providersOfDep(dep)
 	if err != nil {
 		return err
 	}
 
 	// If the container does not support array inferencing,
 	// there should be at most one provider.
-	if (!c.inferLists || !(dep.IsArray || dep.IsSlice)) && len(providers) > 1 {
+	if (!c.inferLists || !(dep.IsArray || dep.IsSlice)) &&
+		dep.Group == "" && !c.isKeyedDep(dep) && len(providers) > 1 {
 		return errors.Newf(expected1ProviderErrMsg, len(providers))
 	}
 
@@ -50,10 +140,43 @@ func (c *Container) buildDependencyForNode(
 		if provider == node {
 			continue
 		}
-		if err := c.graph.AddEdge(provider, node); err != nil {
+		if err := g.AddEdge(provider, node); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// providersOfDep returns the nodes which provide the given dependency,
+// which are the members of its group if it requests one, among those
+// visible from the module the dependency is resolved from.
+func (c *Container) providersOfDep(dep *reflect.Arg) ([]*types.Node, error) {
+	if dep.Group != "" {
+		if !dep.IsSlice {
+			return nil, errors.Newf(groupNotSliceErrMsg, dep.Group, dep.Type)
+		}
+		return c.registry.LookupGroup(dep.Type.Elem(), dep.Group, dep.Scope), nil
+	}
+	if c.isKeyedDep(dep) {
+		return c.registry.LookupKeyed(
+			dep.Type.Elem(), dep.Scope, dep.IsVariadic || dep.Optional,
+		)
+	}
+	return c.registry.LookupNamed(
+		dep.Type, dep.Name, dep.Scope, dep.IsVariadic || dep.Optional,
+	)
+}
+
+// isKeyedDep returns true if the given dependency is a map with string
+// keys, which is collected from the named providers of its element type
+// under list inference. A map which is provided as is, or requested by
+// name, is resolved like any other type.
+func (c *Container) isKeyedDep(dep *reflect.Arg) bool {
+	if !c.inferLists || !dep.IsMap || dep.Name != "" ||
+		dep.Type.Key().Kind() != reflect.String {
+		return false
+	}
+	providers, _ := c.registry.LookupNamed(dep.Type, "", dep.Scope, true)
+	return len(providers) == 0
+}
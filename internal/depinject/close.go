@@ -0,0 +1,86 @@
+package depinject
+
+import (
+	"io"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+)
+
+const closeErrorName = "close"
+
+// cleanup is a function which releases the resources of a resolved node.
+type cleanup struct {
+	id string
+	fn func() error
+}
+
+// Close runs the cleanup functions returned by the container's
+// constructors, in the reverse order the constructors were resolved.
+// This means a value is always cleaned up before the values it depends on.
+// All cleanups are run, even if some of them fail, and their errors are
+// returned together. Each cleanup is only ever run once.
+func (c *Container) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.interceptError(c.close())
+}
+
+func (c *Container) close() error {
+	c.cleanupsMu.Lock()
+	cleanups := c.cleanups
+	c.cleanups = nil
+	c.cleanupsMu.Unlock()
+
+	var errs []error
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := cleanups[i].fn(); err != nil {
+			errs = append(
+				errs, newContainerError(err, closeErrorName, cleanups[i].id),
+			)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// registerCleanups registers the cleanups of a node which has just been
+// resolved. This is safe to call from concurrent resolutions.
+func (c *Container) registerCleanups(node *types.Node) {
+	cleanups := c.cleanupsOf(node)
+
+	c.cleanupsMu.Lock()
+	defer c.cleanupsMu.Unlock()
+	c.cleanups = append(c.cleanups, cleanups...)
+}
+
+// discard runs the cleanups of a node whose constructor returned after
+// its context was done, since its values are never injected. Errors are
+// logged, as there is no caller left to return them to.
+func (c *Container) discard(node *types.Node) {
+	for _, cleanup := range c.cleanupsOf(node) {
+		if err := cleanup.fn(); err != nil {
+			c.logger.Println(newContainerError(err, closeErrorName, cleanup.id))
+		}
+	}
+}
+
+// cleanupsOf returns the cleanups of the given resolved node.
+func (c *Container) cleanupsOf(node *types.Node) []*cleanup {
+	cleanups := make([]*cleanup, 0)
+	if fn := node.Cleanup(); fn != nil {
+		cleanups = append(cleanups, &cleanup{id: node.ID(), fn: fn})
+	}
+	if c.autoClose && !node.Supplied() {
+		for _, value := range node.Values() {
+			if reflect.IsNil(value) {
+				continue
+			}
+			if closer, ok := value.Interface().(io.Closer); ok {
+				cleanups = append(cleanups, &cleanup{id: node.ID(), fn: closer.Close})
+			}
+		}
+	}
+	return cleanups
+}
@@ -4,34 +4,79 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
 	"github.com/skjdfhkskjds/depinject/internal/graph"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
 )
 
 type Container struct {
+	// mu guards the container's graph, registry and resolution state,
+	// allowing the container to be used from multiple goroutines.
+	mu sync.RWMutex
+
 	graph    *graph.DAG[*types.Node]
 	registry *types.Registry
 
 	// The logger used handle the container's error info.
 	logger *log.Logger
 
+	// The providers which were not registered because they are not
+	// active, which are only listed when dumping the registry.
+	inactive []*inactiveNode
+
+	// The dependencies invoked or populated from the container so far,
+	// guarded by rootsMu.
+	roots   []*reflect.Arg
+	rootsMu sync.Mutex
+
+	// Whether the container rejects new providers.
+	sealed bool
+
+	// The providers of the dependencies of each node when the container
+	// was last built, to find the nodes whose dependencies have changed.
+	inputs map[*types.Node]map[*types.Node]struct{}
+
 	// Whether the container is ready to be invoked.
 	invokable bool
 
+	// The session of the resolution which holds the write lock, if any,
+	// which factories created in earlier sessions join when they are
+	// called by the constructors being resolved.
+	resolving atomic.Pointer[session]
+
+	// The nodes being resolved by each chain, and the nodes each chain
+	// waits for.
+	waits waits
+
 	// Sorted nodes in topological order.
 	sortedNodes []*types.Node
 
+	// The In structs whose constructors are registered, which are shared
+	// by every constructor that takes them.
+	inSentinels map[inSentinelKey]struct{}
+
+	// The providers which are constructed by assisted factories, and the
+	// indices of the dependencies which the factories pass to them.
+	assisted map[*types.Node]map[int]struct{}
+
 	// Options
-	// Instructs the container to enable the use of sentinel
-	// structs in constructor arguments and parses the struct's
-	// fields as constructor arguments.
-	useInSentinel bool
+	// Instructs the container to not parse the fields of sentinel
+	// structs as constructor arguments and outputs.
+	disableSentinels bool
 
-	// Instructs the container to enable the use of sentinel
-	// structs in constructor outputs and parses the struct's
-	// fields as constructor outputs.
-	useOutSentinel bool
+	// Instructs the container to seal itself once it is resolved.
+	autoSeal bool
+
+	// Instructs the container to fail to build if a provider is not
+	// used by any of the given types, when strictUnused is true.
+	strictUnused bool
+	strictRoots  []reflect.Type
+
+	// The profiles which are active in the container.
+	profiles []string
 
 	// Allows the container to match dependencies that are interfaces
 	// to types which are implementations of those interfaces.
@@ -40,18 +85,34 @@ type Container struct {
 	// Allows the container to have multiple constructors with the same
 	// output type, and will process them as lists (slices or arrays).
 	inferLists bool
+
+	// The maximum number of constructors to execute concurrently during
+	// resolution. Values less than 2 resolve constructors sequentially.
+	workers int
+
+	// Instructs the container to not recover panics in constructors.
+	panicPassthrough bool
+
+	// Instructs the container to register the Close method of every
+	// constructed value which implements io.Closer as a cleanup.
+	autoClose bool
+
+	// The cleanups registered by resolved constructors, in the order
+	// the constructors were resolved.
+	cleanups   []*cleanup
+	cleanupsMu sync.Mutex
 }
 
 // DefaultContainer returns a new container with the default options.
 func DefaultContainer() *Container {
 	return &Container{
-		logger:          log.Default(),
-		invokable:       false,
-		sortedNodes:     nil,
-		useInSentinel:   false,
-		useOutSentinel:  false,
-		inferInterfaces: false,
-		inferLists:      false,
+		logger:           log.Default(),
+		invokable:        false,
+		sortedNodes:      nil,
+		disableSentinels: false,
+		inferInterfaces:  false,
+		inferLists:       false,
+		workers:          1,
 	}
 }
 
@@ -64,15 +125,44 @@ func NewContainer(opts ...Option) *Container {
 
 	c.graph = graph.NewDAG[*types.Node](!c.inferLists)
 	c.registry = types.NewRegistry(c.inferLists, c.inferInterfaces)
+	c.inSentinels = make(map[inSentinelKey]struct{})
 	return c
 }
 
-// Destroy destroys the container and frees its memory.
+// Seal prevents any more providers from being registered with the
+// container. Provide and Supply return ErrContainerSealed once the
+// container is sealed, so that the providers which have been resolved
+// are never re-executed.
+func (c *Container) Seal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sealed = true
+}
+
+// Sealed returns true if the container has been sealed.
+func (c *Container) Sealed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.sealed
+}
+
+// Destroy closes the container and frees its memory.
 func (c *Container) Destroy() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Destroy has no way to report errors, so they are only logged.
+	_ = c.interceptError(c.close())
+
 	c.graph = nil
 	c.registry = nil
+	c.inactive = nil
+	c.inputs = nil
+	c.roots = nil
 	c.sortedNodes = nil
-	c = nil
+	c.invokable = false
 }
 
 const (
@@ -89,7 +179,7 @@ func (c *Container) interceptError(receivedErr error) error {
 
 	// Create header with dynamic width
 	errStr := receivedErr.Error()
-	regDump := c.registry.Dump()
+	regDump := c.registry.Dump() + c.dumpInactive()
 	maxLen := 0
 	// Find max line length across all lines
 	for _, line := range strings.Split(errStr, "\n") {
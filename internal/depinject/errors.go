@@ -2,8 +2,20 @@ package depinject
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/skjdfhkskjds/depinject/internal/errors"
 )
 
+// ErrUnusedProviders is returned when building a container which is
+// strict about unused providers, or when checking for unused providers,
+// and a provider is not used by any of the given types.
+var ErrUnusedProviders = errors.New("unused providers")
+
+// ErrContainerSealed is returned when registering a provider with a
+// container which has been sealed.
+var ErrContainerSealed = errors.New("container is sealed")
+
 const (
 	// expected1ProviderErrMsg is the error message for when the
 	// expected number of providers does not match the actual number
@@ -17,6 +29,56 @@ const (
 	// sliceElementTypesMismatchErrMsg is the error message for when the
 	// element types of a slice do not match the expected type.
 	sliceElementTypesMismatchErrMsg = "slice element types mismatch: %s != %s"
+
+	// duplicateMapKeyErrMsg is the error message for when several
+	// providers of the values of a map are named the same.
+	duplicateMapKeyErrMsg = "duplicate key %q for %s"
+
+	// lazyCycleErrMsg is the error message for when a lazy dependency
+	// requires a node which is still being constructed.
+	lazyCycleErrMsg = "%w: %s"
+
+	// invalidAssistedFactoryErrMsg is the error message for when the
+	// function of an assisted factory does not return a value and an
+	// error.
+	invalidAssistedFactoryErrMsg = "assisted factory %s must return a value and an error"
+
+	// unmatchedAssistedParamErrMsg is the error message for when a
+	// parameter of an assisted factory does not match any of the
+	// provider's arguments.
+	unmatchedAssistedParamErrMsg = "parameter of type %s does not match any argument of %s"
+
+	// assistedProviderErrMsg is the error message for when a provider
+	// which is constructed by assisted factories is depended on directly.
+	assistedProviderErrMsg = "provider %s requires runtime parameters, and can only be used through a factory"
+
+	// invalidPopulateTargetErrMsg is the error message for when the
+	// target of Populate is not a non-nil pointer to a struct.
+	invalidPopulateTargetErrMsg = "populate target must be a non-nil pointer to a struct, got %T"
+
+	// groupNotSliceErrMsg is the error message for when a group is
+	// requested by a dependency which is not a slice.
+	groupNotSliceErrMsg = "group %q must be requested as a slice, got %s"
+
+	// indistinguishableFieldsErrMsg is the error message for when two
+	// fields of a struct have the same type, name and group.
+	indistinguishableFieldsErrMsg = "fields %s and %s of %s have the same type %s, and must be told apart by name or group tags"
+
+	// nameAndGroupErrMsg is the error message for when a sentinel
+	// struct field has both a name and a group tag.
+	nameAndGroupErrMsg = "field %s cannot have both a name and a group"
+
+	// invalidOptionalTagErrMsg is the error message for when the
+	// optional tag of a sentinel struct field is not a boolean.
+	invalidOptionalTagErrMsg = "invalid optional tag %q on field %s"
+
+	// unknownTagModifierErrMsg is the error message for when a struct
+	// tag has a modifier which is not supported.
+	unknownTagModifierErrMsg = "unknown tag modifier %q on field %s"
+
+	// unusedProvidersErrMsg is the error message for when providers are
+	// not used by any of the types invoked from the container.
+	unusedProvidersErrMsg = "%w: %s"
 )
 
 var _ error = (*containerError)(nil)
@@ -56,3 +118,42 @@ func (e *containerError) Error() string {
 
 	return msg
 }
+
+// Unwrap returns the error which caused the container error.
+func (e *containerError) Unwrap() error {
+	return e.root
+}
+
+var _ error = (*ConstructorError)(nil)
+
+// ConstructorError is returned when a constructor panics while the
+// container is being resolved.
+type ConstructorError struct {
+	// ID is the ID of the node whose constructor panicked.
+	ID string
+
+	// Path is the chain of nodes through which a type invoked from
+	// the container depends on the constructor, starting from the
+	// provider of the invoked type and ending with the constructor
+	// itself. It only holds the constructor if no invoked type
+	// depends on it.
+	Path []string
+
+	// Value is the value the constructor panicked with.
+	Value any
+
+	// Stack is the stack trace of the constructor at the time of
+	// the panic.
+	Stack []byte
+}
+
+// Error returns a single line describing the panic. The stack trace is
+// left out, and can be read from the Stack field.
+func (e *ConstructorError) Error() string {
+	return fmt.Sprintf(
+		"Error in constructor: on %s got: panic: %v (dependency path: %s)",
+		e.ID,
+		e.Value,
+		strings.Join(e.Path, " -> "),
+	)
+}
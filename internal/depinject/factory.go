@@ -0,0 +1,127 @@
+package depinject
+
+import (
+	"context"
+	"strings"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+)
+
+const factoryErrorName = "factory"
+
+// modulePath is the import path of the public package, which defines
+// the generic types that the container treats specially.
+const modulePath = "github.com/skjdfhkskjds/depinject"
+
+// factoryOf returns the type produced by the given type if it is a
+// factory, that is a depinject.Provider[T], a depinject.Lazy[T] or a
+// func() (T, error) which is not itself provided to the container.
+func (c *Container) factoryOf(t reflect.Type) (reflect.Type, bool) {
+	if !reflect.IsFunc(t) || t.NumIn() != 0 || t.NumOut() != 2 ||
+		t.Out(1) != reflect.ErrorType {
+		return nil, false
+	}
+	if isGenericType(t, "Provider") || isLazy(t) {
+		return t.Out(0), true
+	}
+
+	// A plain func() (T, error) is only a factory if no constructor
+	// provides it as is.
+	providers, _ := c.registry.Lookup(t, true)
+	return t.Out(0), len(providers) == 0
+}
+
+// producedBy returns the type of the value produced by the given type if
+// it is a factory or an assisted factory.
+func (c *Container) producedBy(t reflect.Type) (reflect.Type, bool) {
+	if fnType, ok := assistedFactoryOf(t); ok {
+		if !reflect.IsFunc(fnType) || fnType.NumOut() == 0 {
+			return nil, false
+		}
+		return fnType.Out(0), true
+	}
+	return c.factoryOf(t)
+}
+
+// newFactory returns a function of the type of the given factory
+// dependency which, when called, returns a value of type t from the
+// container, as seen from the module the dependency is resolved from.
+// The value is constructed on the first call if it has not been already,
+// or on every call if it is provided by a transient constructor.
+func (c *Container) newFactory(
+	ctx context.Context, dep *reflect.Arg, t reflect.Type,
+) reflect.Value {
+	return reflect.MakeFunc(dep.Type, func([]reflect.Value) []reflect.Value {
+		out := reflect.New(t).Elem()
+		errOut := reflect.New(reflect.ErrorType).Elem()
+
+		value, err := c.callFactory(ctx, t, dep.Scope)
+		if err != nil {
+			errOut.Set(reflect.ValueOf(err))
+		} else {
+			out.Set(value)
+		}
+		return []reflect.Value{out, errOut}
+	})
+}
+
+// callFactory returns a value of type t from the container, as seen from
+// the given module, constructing it if needed.
+func (c *Container) callFactory(
+	ctx context.Context, t reflect.Type, scope string,
+) (reflect.Value, error) {
+	ctx, release := c.joinSession(ctx)
+	defer release()
+
+	dep := reflect.NewArg(t, false)
+	dep.Scope = scope
+	value, _, err := c.valueOfDep(ctx, dep)
+	if err != nil {
+		return reflect.Value{}, newContainerError(err, factoryErrorName, t.String())
+	}
+	return value, nil
+}
+
+// isLazy returns true if the given type is a depinject.Lazy[T].
+func isLazy(t reflect.Type) bool {
+	return isGenericType(t, "Lazy")
+}
+
+// buildFactoryDependencyForNode validates that the type produced by a
+// factory dependency is provided, and that depending on it would not
+// create a cycle. Factory dependencies do not require the value to be
+// constructed before the node, so they are added as weak edges. Lazy
+// dependencies are exempt from the cycle check, and add no edge at all.
+func (c *Container) buildFactoryDependencyForNode(
+	g *graph.DAG[*types.Node], node *types.Node, t reflect.Type, lazy bool,
+) error {
+	providers, err := c.registry.LookupNamed(t, "", node.Module(), false)
+	if err != nil {
+		return err
+	}
+
+	if (!c.inferLists || !(t.Kind() == reflect.Slice || t.Kind() == reflect.Array)) &&
+		len(providers) > 1 {
+		return errors.Newf(expected1ProviderErrMsg, len(providers))
+	}
+
+	if lazy {
+		return nil
+	}
+
+	for _, provider := range providers {
+		if err = g.AddWeakEdge(provider, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isGenericType returns true if the given type is an instantiation of
+// the generic type with the given name from the public package.
+func isGenericType(t reflect.Type, name string) bool {
+	return t.PkgPath() == modulePath && strings.HasPrefix(t.Name(), name+"[")
+}
@@ -0,0 +1,182 @@
+package depinject
+
+import (
+	"slices"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+	"github.com/skjdfhkskjds/depinject/internal/utils"
+)
+
+const introspectErrorName = "introspect"
+
+// ProviderInfo describes a provider registered in the container.
+type ProviderInfo struct {
+	// ID is the identifier of the provider, as reported in errors.
+	ID string
+
+	// Outputs are the types of the values the provider returns.
+	Outputs []reflect.Type
+
+	// Dependencies are the types of the provider's arguments.
+	Dependencies []reflect.Type
+
+	// Source is the file and line the provider's constructor is declared
+	// at, or that of the constructor it was generated from. It is empty
+	// for supplied values and constructors generated by the container.
+	Source string
+
+	// Name and Group are the name the provider's values are provided
+	// under, and the group they are contributed to, if any.
+	Name  string
+	Group string
+
+	// Module is the path of the module the provider was provided in, and
+	// Private is true if its values are only visible to that module.
+	Module  string
+	Private bool
+
+	// Transient is true if the provider is executed for every value it
+	// needs to inject.
+	Transient bool
+
+	// Supplied is true if the provider's values were supplied to the
+	// container rather than constructed.
+	Supplied bool
+}
+
+// newProviderInfo returns the description of the given node.
+func newProviderInfo(node *types.Node) ProviderInfo {
+	return ProviderInfo{
+		ID:      node.ID(),
+		Outputs: node.Outputs(),
+		Dependencies: utils.MapSlice(
+			node.Dependencies(),
+			func(arg *reflect.Arg) reflect.Type { return arg.Type },
+		),
+		Source:    node.Location(),
+		Name:      node.Name(),
+		Group:     node.Group(),
+		Module:    node.Module(),
+		Private:   node.Private(),
+		Transient: node.Transient(),
+		Supplied:  node.Supplied(),
+	}
+}
+
+// Providers returns the providers registered in the container, in the
+// order they were registered.
+func (c *Container) Providers() []ProviderInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return utils.MapSlice(c.graph.Vertices(), newProviderInfo)
+}
+
+// Has returns true if the container has an unnamed provider of the
+// given type, including implementations of the type if the container
+// infers interfaces.
+func (c *Container) Has(t reflect.Type) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	providers, _ := c.registry.Lookup(t, true)
+	return len(providers) > 0
+}
+
+// DependenciesOf returns the providers which the providers of the given
+// type depend on directly, including through factories.
+func (c *Container) DependenciesOf(t reflect.Type) ([]ProviderInfo, error) {
+	return c.neighborsOf(t, func(
+		g *graph.DAG[*types.Node], node *types.Node,
+	) []*types.Node {
+		return g.Predecessors(node)
+	})
+}
+
+// DependentsOf returns the providers which depend directly on the
+// providers of the given type, including through factories.
+func (c *Container) DependentsOf(t reflect.Type) ([]ProviderInfo, error) {
+	return c.neighborsOf(t, func(
+		g *graph.DAG[*types.Node], node *types.Node,
+	) []*types.Node {
+		return slices.Concat(g.Successors(node), g.WeakSuccessors(node))
+	})
+}
+
+// Order returns the providers in the order the container resolves them,
+// such that every provider comes after the providers it depends on.
+func (c *Container) Order() ([]ProviderInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.invokable {
+		return utils.MapSlice(c.sortedNodes, newProviderInfo), nil
+	}
+	g, err := c.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := g.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+	return utils.MapSlice(nodes, newProviderInfo), nil
+}
+
+// neighborsOf returns the neighbors of the providers of the given type,
+// once each.
+func (c *Container) neighborsOf(
+	t reflect.Type,
+	neighbors func(*graph.DAG[*types.Node], *types.Node) []*types.Node,
+) ([]ProviderInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	g, err := c.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	providers, err := c.registry.Lookup(t, false)
+	if err != nil {
+		return nil, newContainerError(err, introspectErrorName, t.String())
+	}
+
+	infos := make([]ProviderInfo, 0)
+	seen := make(map[*types.Node]bool)
+	for _, provider := range providers {
+		for _, neighbor := range neighbors(g, provider) {
+			if !seen[neighbor] {
+				seen[neighbor] = true
+				infos = append(infos, newProviderInfo(neighbor))
+			}
+		}
+	}
+	return infos, nil
+}
+
+// snapshot returns the container's graph. If providers have been
+// registered since the container was last built, they are built into a
+// new graph instead, so that the container itself is left untouched.
+// The caller must hold at least the read lock.
+func (c *Container) snapshot() (*graph.DAG[*types.Node], error) {
+	if c.invokable {
+		return c.graph, nil
+	}
+
+	g := graph.NewDAG[*types.Node](!c.inferLists)
+	for _, node := range c.graph.Vertices() {
+		if err := g.AddVertex(node); err != nil {
+			return nil, err
+		}
+	}
+	assisted, err := c.assistedProviders()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.buildEdges(g, assisted); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
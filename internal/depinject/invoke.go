@@ -1,7 +1,8 @@
 package depinject
 
 import (
-	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"context"
+
 	"github.com/skjdfhkskjds/depinject/internal/reflect"
 )
 
@@ -10,20 +11,37 @@ const invokeErrorName = "invoke"
 // Invoke is a public function that allows for the invocation of
 // values from the container. This function should be called after
 // all required values and providers have been registered.
+// It is safe to call Invoke concurrently, including concurrently with
+// Provide and Supply, in which case the container is rebuilt before
+// the outputs are assigned.
 func (c *Container) Invoke(outputs ...any) error {
-	if !c.invokable {
-		var err error
-		if err = c.build(); err != nil {
-			return c.interceptError(err)
-		}
-		if err = c.resolve(); err != nil {
-			return c.interceptError(err)
+	return c.InvokeContext(context.Background(), outputs...)
+}
+
+// InvokeContext is the same as Invoke, but resolves the container with
+// the given context. Constructors which request a context.Context
+// receive this context, and if it is done before resolution completes,
+// the remaining constructors are skipped.
+func (c *Container) InvokeContext(ctx context.Context, outputs ...any) error {
+	roots := make([]*reflect.Arg, 0, len(outputs))
+	for _, output := range outputs {
+		if outputType := reflect.TypeOf(output); outputType != nil &&
+			outputType.Kind() == reflect.Ptr {
+			roots = append(roots, reflect.NewArg(outputType.Elem(), false))
 		}
-		c.invokable = true
 	}
+	c.addRoots(roots)
+
+	if err := c.rlockInvokable(ctx); err != nil {
+		return err
+	}
+	defer c.mu.RUnlock()
+
+	ctx, s := newSession(ctx)
+	defer s.finish()
 
 	for _, output := range outputs {
-		if err := c.invoke(output); err != nil {
+		if err := c.invoke(ctx, output); err != nil {
 			return c.interceptError(newContainerError(
 				err, invokeErrorName, reflect.TypeOf(output).Elem().String(),
 			))
@@ -32,7 +50,63 @@ func (c *Container) Invoke(outputs ...any) error {
 	return nil
 }
 
-func (c *Container) invoke(output any) error {
+// rlockInvokable acquires the read lock once the container is invokable,
+// building and resolving it first if needed. If it returns nil, the
+// caller must release the read lock.
+func (c *Container) rlockInvokable(ctx context.Context) error {
+	// Values are only ever read once the container is invokable, so
+	// multiple invocations can share the read lock. If the container
+	// needs to be (re)built, we upgrade to the write lock and try again,
+	// since a provider may have been registered in between.
+	c.mu.RLock()
+	for !c.invokable {
+		c.mu.RUnlock()
+		if err := c.prepare(ctx); err != nil {
+			return err
+		}
+		c.mu.RLock()
+	}
+	return nil
+}
+
+// prepare builds and resolves the container if it is not yet invokable.
+func (c *Container) prepare(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.invokable {
+		return nil
+	}
+
+	var err error
+	if err = c.build(); err != nil {
+		return c.interceptError(err)
+	}
+	if c.strictUnused {
+		err = c.checkUnused(c.strictRoots, c.assisted, buildErrorName)
+		if err != nil {
+			return c.interceptError(err)
+		}
+	}
+
+	// Only the nodes which are new, or whose dependencies have changed
+	// since the last resolution, are resolved again, so that singletons
+	// which are already resolved keep their values.
+	c.unresolveChanged()
+
+	ctx, s := newSession(ctx)
+	defer s.finish()
+	c.resolving.Store(s)
+	defer c.resolving.Store(nil)
+	if err = c.resolve(ctx); err != nil {
+		return c.interceptError(err)
+	}
+	c.invokable = true
+	c.sealed = c.sealed || c.autoSeal
+	return nil
+}
+
+func (c *Container) invoke(ctx context.Context, output any) error {
 	// Infer the type of the output using reflect
 	outputType := reflect.TypeOf(output)
 
@@ -41,23 +115,30 @@ func (c *Container) invoke(output any) error {
 		outputType = outputType.Elem()
 	}
 
-	// Search the registry for any value which matches the type of v
-	providers, err := c.registry.Lookup(outputType, false)
+	value, ok, err := c.valueOfOutput(ctx, reflect.NewArg(outputType, false))
 	if err != nil {
 		return err
-	}
-
-	// TODO: add support for array referencing on invoke.
-	if len(providers) != 1 {
-		return errors.Newf(expected1ProviderErrMsg, len(providers))
+	} else if !ok {
+		return nil
 	}
 
 	// Assign the value to the output
-	value, err := providers[0].ValueOf(outputType, false, c.inferInterfaces)
-	if err != nil {
-		return err
-	}
 	reflect.ValueOf(output).Elem().Set(value)
 
 	return nil
 }
+
+// valueOfOutput returns the value which satisfies the given dependency
+// for a caller outside of the container. Factories are created rather
+// than looked up, and remain bound to the container once the caller
+// returns. Otherwise, the dependency is resolved the same way a
+// constructor argument of the same type would be, so that slices and
+// arrays are supported under list inference.
+func (c *Container) valueOfOutput(
+	ctx context.Context, dep *reflect.Arg,
+) (reflect.Value, bool, error) {
+	if t, ok := c.factoryOf(dep.Type); ok {
+		return c.newFactory(ctx, dep, t), true, nil
+	}
+	return c.valueOfDep(ctx, dep)
+}
@@ -0,0 +1,58 @@
+package depinject
+
+import (
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+)
+
+// moduleSeparator separates the names of nested modules in their paths.
+const moduleSeparator = "/"
+
+// A Module is a named part of a container, which constructors can be
+// provided in. Constructors annotated with Private in a module are only
+// visible to the constructors of that module and of its submodules, and
+// shadow the providers of the same type outside of it.
+//
+//	billing := container.Module("billing")
+//	billing.Provide(NewHTTPClient, depinject.Private())
+//	billing.Provide(NewInvoicer)
+type Module struct {
+	c    *Container
+	path string
+}
+
+// Module returns the module of the container with the given name.
+// Modules with the same name share their private providers.
+func (c *Container) Module(name string) *Module {
+	return &Module{c: c, path: name}
+}
+
+// Module returns the submodule of the module with the given name, which
+// sees the private providers of the module.
+func (m *Module) Module(name string) *Module {
+	return &Module{c: m.c, path: m.path + moduleSeparator + name}
+}
+
+// Path returns the names of the module and of the modules above it,
+// separated by slashes.
+func (m *Module) Path() string {
+	return m.path
+}
+
+// Provide provides the given constructors into the module's container,
+// resolving their dependencies from the module.
+func (m *Module) Provide(constructors ...any) error {
+	return m.c.Provide(append(constructors, inModule(m.path))...)
+}
+
+// ProvideIf provides the given constructors into the module's container
+// in the same way as Provide if the predicate returns true.
+func (m *Module) ProvideIf(predicate func() bool, constructors ...any) error {
+	return m.c.ProvideIf(predicate, append(constructors, inModule(m.path))...)
+}
+
+// inModule places the constructor in the module with the given path.
+func inModule(path string) Annotation {
+	return func(n *types.Node) {
+		n.SetModule(path)
+	}
+}
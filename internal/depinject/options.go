@@ -1,6 +1,10 @@
 package depinject
 
-import "log"
+import (
+	"log"
+
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+)
 
 type Option func(*Container)
 
@@ -11,21 +15,54 @@ func WithLogger(l *log.Logger) Option {
 	}
 }
 
-// Instructs the container to enable the use of sentinel
-// structs in constructor arguments and parses the struct's
-// fields as constructor arguments.
+// Deprecated: Sentinel structs in constructor arguments are
+// parsed by default, so this option has no effect.
 func WithInSentinel() Option {
+	return func(*Container) {}
+}
+
+// Deprecated: Sentinel structs in constructor outputs are
+// parsed by default, so this option has no effect.
+func WithOutSentinel() Option {
+	return func(*Container) {}
+}
+
+// Instructs the container to not parse the fields of sentinel
+// structs as constructor arguments and outputs, and to treat
+// the structs as any other type instead.
+func WithoutSentinels() Option {
 	return func(c *Container) {
-		c.useInSentinel = true
+		c.disableSentinels = true
 	}
 }
 
-// Instructs the container to enable the use of sentinel
-// structs in constructor outputs and parses the struct's
-// fields as constructor outputs.
-func WithOutSentinel() Option {
+// Instructs the container to seal itself once it is first resolved, by
+// Invoke or Populate, rejecting any later providers with
+// ErrContainerSealed.
+func WithAutoSeal() Option {
 	return func(c *Container) {
-		c.useOutSentinel = true
+		c.autoSeal = true
+	}
+}
+
+// Instructs the container to fail to build if a provider is not used,
+// directly or indirectly, by the given root or any of the other given
+// types, so that unused providers can be caught in tests. The types the
+// container is invoked for are not taken into account, so that the
+// check does not depend on the order of invocations.
+func WithStrictUnused(root reflect.Type, roots ...reflect.Type) Option {
+	return func(c *Container) {
+		c.strictUnused = true
+		c.strictRoots = append([]reflect.Type{root}, roots...)
+	}
+}
+
+// Sets the profiles which are active in the container. Constructors
+// annotated with Profile are only provided if one of their profiles
+// is active.
+func WithProfiles(profiles ...string) Option {
+	return func(c *Container) {
+		c.profiles = append(c.profiles, profiles...)
 	}
 }
 
@@ -39,8 +76,36 @@ func WithInterfaceInference() Option {
 
 // Allows the container to have multiple constructors with the same
 // output type, and will process them as lists (slices or arrays).
+// Named values are also collected into maps keyed by their names.
 func WithListInference() Option {
 	return func(c *Container) {
 		c.inferLists = true
 	}
 }
+
+// Instructs the container to execute independent constructors
+// concurrently, using at most the given number of workers. A
+// constructor is executed once all of its dependencies are resolved.
+func WithParallelResolution(workers int) Option {
+	return func(c *Container) {
+		c.workers = workers
+	}
+}
+
+// Instructs the container to not recover panics in constructors,
+// letting them crash the program. By default, panics are recovered
+// and returned as a *ConstructorError.
+func WithPanicPassthrough() Option {
+	return func(c *Container) {
+		c.panicPassthrough = true
+	}
+}
+
+// Instructs the container to close every value constructed by a
+// constructor which implements io.Closer when the container is closed.
+// Supplied values are not closed, since they are owned by the caller.
+func WithAutoClose() Option {
+	return func(c *Container) {
+		c.autoClose = true
+	}
+}
@@ -0,0 +1,128 @@
+package depinject
+
+import (
+	"context"
+	"strings"
+
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+)
+
+const (
+	populateErrorName = "populate"
+
+	// injectTag is the struct tag which marks the fields to populate.
+	injectTag = "inject"
+
+	// skipTag is the inject tag value which excludes a field from the
+	// dependencies of a struct constructor.
+	skipTag = "-"
+
+	// optionalModifier is the inject tag modifier which allows a field
+	// to be left as is if it has no providers.
+	optionalModifier = "optional"
+)
+
+// Populate is a public function that sets the fields of an existing
+// struct, passed as a pointer, to values from the container. Only the
+// exported fields tagged with `inject` are set. The tag holds the name
+// of the provider to resolve the field from, followed by optional
+// modifiers:
+//
+//	type Server struct {
+//		DB      *DB     `inject:""`
+//		Replica *DB     `inject:"replica"`
+//		Tracer  *Tracer `inject:",optional"`
+//	}
+//
+// Optional fields are left as is if they have no providers.
+func (c *Container) Populate(target any) error {
+	c.addRoots(populateRoots(target))
+
+	ctx := context.Background()
+	if err := c.rlockInvokable(ctx); err != nil {
+		return err
+	}
+	defer c.mu.RUnlock()
+
+	ctx, s := newSession(ctx)
+	defer s.finish()
+
+	if err := c.populate(ctx, target); err != nil {
+		return c.interceptError(newContainerError(
+			err, populateErrorName, reflect.TypeOf(target).String(),
+		))
+	}
+	return nil
+}
+
+func (c *Container) populate(ctx context.Context, target any) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return errors.Newf(invalidPopulateTargetErrMsg, target)
+	}
+
+	s, err := reflect.NewStruct(targetValue.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	structValue := targetValue.Elem()
+	for _, field := range s.FieldsWithTag(injectTag) {
+		dep := reflect.NewArg(field.Type, false)
+		if err = applyInjectTag(dep, field); err != nil {
+			return err
+		}
+
+		value, ok, err := c.valueOfOutput(ctx, dep)
+		if err != nil {
+			return newContainerError(err, populateErrorName, field.Name)
+		} else if !ok {
+			continue
+		}
+		structValue.FieldByIndex(field.Index).Set(value)
+	}
+	return nil
+}
+
+// populateRoots returns the dependencies of the fields of the target
+// which Populate sets, or none if the target is invalid.
+func populateRoots(target any) []*reflect.Arg {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
+		return nil
+	}
+	s, err := reflect.NewStruct(targetType.Elem())
+	if err != nil {
+		return nil
+	}
+
+	roots := make([]*reflect.Arg, 0)
+	for _, field := range s.FieldsWithTag(injectTag) {
+		dep := reflect.NewArg(field.Type, false)
+		if applyInjectTag(dep, field) == nil {
+			roots = append(roots, dep)
+		}
+	}
+	return roots
+}
+
+// applyInjectTag sets the name and modifiers of the given dependency from
+// the inject tag of the field it is resolved for.
+func applyInjectTag(dep *reflect.Arg, field *reflect.StructField) error {
+	name, modifiers, _ := strings.Cut(field.Tag.Get(injectTag), ",")
+	dep.Name = strings.TrimSpace(name)
+	if modifiers == "" {
+		return nil
+	}
+
+	for _, modifier := range strings.Split(modifiers, ",") {
+		switch modifier = strings.TrimSpace(modifier); modifier {
+		case optionalModifier:
+			dep.Optional = true
+		default:
+			return errors.Newf(unknownTagModifierErrMsg, modifier, field.Name)
+		}
+	}
+	return nil
+}
@@ -1,25 +1,73 @@
 package depinject
 
-import "github.com/skjdfhkskjds/depinject/internal/depinject/types"
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+)
 
 const provideErrorName = "provide"
 
 // Provide is a public function that allows for the injection of
 // constructors into the container. Constructors are functions
 // that return a value of some type.
+// Any annotations in the list are applied to every constructor.
 func (c *Container) Provide(constructors ...any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.provideAll(constructors, true)
+}
+
+// ProvideIf provides the given constructors in the same way as Provide
+// if the predicate returns true. Otherwise, the constructors are still
+// checked, but are not registered, and are only listed as inactive when
+// the container reports an error.
+func (c *Container) ProvideIf(predicate func() bool, constructors ...any) error {
+	active := predicate()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.provideAll(constructors, active)
+}
+
+func (c *Container) provideAll(constructors []any, active bool) error {
+	constructors, annotations := splitAnnotations(constructors)
 	for _, constructor := range constructors {
-		if err := c.provide(constructor); err != nil {
+		if err := c.provide(constructor, annotations, active); err != nil {
 			return c.interceptError(err)
 		}
 	}
 	return nil
 }
 
-func (c *Container) provide(constructor any) error {
-	node, err := types.NewNode(constructor)
+func (c *Container) provide(
+	constructor any, annotations []Annotation, active bool,
+) error {
+	node, err := newNode(constructor)
 	if err != nil {
-		return newContainerError(err, provideErrorName, node.ID())
+		return newContainerError(
+			err, provideErrorName, fmt.Sprintf("%T", constructor),
+		)
+	}
+	for _, annotate := range annotations {
+		annotate(node)
+	}
+	if c.sealed {
+		return newContainerError(ErrContainerSealed, provideErrorName, node.ID())
+	}
+
+	// Inactive nodes never enter the registry or the graph.
+	if !active {
+		c.inactive = append(c.inactive, &inactiveNode{node, conditionReason})
+		return nil
+	} else if !c.profileActive(node) {
+		reason := fmt.Sprintf(profilesReason, strings.Join(node.Profiles(), ","))
+		c.inactive = append(c.inactive, &inactiveNode{node, reason})
+		return nil
 	}
 
 	if err = c.register(node, provideErrorName); err != nil {
@@ -28,3 +76,60 @@ func (c *Container) provide(constructor any) error {
 
 	return nil
 }
+
+// newNode returns a new node for the given constructor, generating the
+// constructor first if it was requested with Struct.
+func newNode(constructor any) (*types.Node, error) {
+	s, ok := constructor.(*structConstructor)
+	if !ok {
+		return types.NewNode(constructor)
+	}
+
+	fn, err := s.constructor()
+	if err != nil {
+		return nil, err
+	}
+	return types.NewNodeFromFunc(fn), nil
+}
+
+// The reasons a provider is inactive, as listed in the registry dump.
+const (
+	conditionReason = "[condition=false]"
+	profilesReason  = "[profiles=%s]"
+)
+
+// An inactiveNode is a provider which was not registered, along with the
+// reason why.
+type inactiveNode struct {
+	node   *types.Node
+	reason string
+}
+
+// profileActive returns true if the node has no profiles, or if one of
+// its profiles is active in the container.
+func (c *Container) profileActive(node *types.Node) bool {
+	if len(node.Profiles()) == 0 {
+		return true
+	}
+	for _, profile := range node.Profiles() {
+		if slices.Contains(c.profiles, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpInactive lists the inactive providers in the format of the
+// registry's dump.
+func (c *Container) dumpInactive() string {
+	if len(c.inactive) == 0 {
+		return ""
+	}
+
+	var dump strings.Builder
+	dump.WriteString("inactive:\n")
+	for _, inactive := range c.inactive {
+		dump.WriteString("\t" + inactive.node.ID() + " " + inactive.reason + "\n")
+	}
+	return dump.String()
+}
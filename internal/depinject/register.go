@@ -2,12 +2,16 @@ package depinject
 
 import (
 	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
 )
 
 func (c *Container) register(
 	node *types.Node,
 	callerErrorName string,
 ) error {
+	node.SetPanicPassthrough(c.panicPassthrough)
+	node.SetDiscardHandler(c.discard)
+
 	var err error
 	if err = c.registerSentinelsForNode(node, callerErrorName); err != nil {
 		return newContainerError(err, callerErrorName, node.ID())
@@ -31,33 +35,45 @@ func (c *Container) register(
 func (c *Container) registerSentinelsForNode(
 	node *types.Node, callerErrorName string,
 ) error {
-	// If in sentinels are enabled, register all applicable arguments
-	// as nodes.
-	if c.useInSentinel {
-		sentinelNodes, err := parseInSentinels(node)
-		if err != nil {
-			return err
-		}
-		for _, n := range sentinelNodes {
-			if err = c.register(n, callerErrorName); err != nil {
-				return err
-			}
-		}
+	if c.disableSentinels {
+		return nil
+	}
+
+	// Register all applicable arguments as nodes.
+	sentinelNodes, err := parseInSentinels(node)
+	if err != nil {
+		return err
+	}
+
+	// Register all applicable outputs as nodes.
+	outSentinelNodes, err := parseOutSentinels(node)
+	if err != nil {
+		return err
 	}
 
-	// If out sentinels are enabled, register all applicable outputs
-	// as nodes.
-	if c.useOutSentinel {
-		sentinelNodes, err := parseOutSentinels(node)
-		if err != nil {
+	for _, n := range sentinelNodes {
+		// Constructors which take the same In struct in the same module
+		// share the node which constructs it.
+		key := inSentinelKey{t: n.Outputs()[0], module: n.Module()}
+		if _, ok := c.inSentinels[key]; ok {
+			continue
+		}
+		if err = c.register(n, callerErrorName); err != nil {
 			return err
 		}
-		for _, n := range sentinelNodes {
-			if err = c.register(n, callerErrorName); err != nil {
-				return err
-			}
+		c.inSentinels[key] = struct{}{}
+	}
+	for _, n := range outSentinelNodes {
+		if err = c.register(n, callerErrorName); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
+
+// inSentinelKey identifies the node which constructs an In struct for
+// the constructors of a module.
+type inSentinelKey struct {
+	t      reflect.Type
+	module string
+}
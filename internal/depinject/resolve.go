@@ -1,8 +1,14 @@
 package depinject
 
 import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
 	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
 	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
 	"github.com/skjdfhkskjds/depinject/internal/reflect"
 )
 
@@ -10,72 +16,379 @@ const resolveErrorName = "resolve"
 
 // resolve resolves the container's nodes in order. By the end of this
 // routine, every node will have been invoked.
-func (c *Container) resolve() error {
+func (c *Container) resolve(ctx context.Context) error {
+	if c.workers > 1 {
+		return c.resolveParallel(ctx)
+	}
+
 	for _, node := range c.sortedNodes {
-		if err := c.resolveNode(node); err != nil {
-			return newContainerError(err, resolveErrorName, node.ID())
+		if err := c.resolveNode(ctx, node); err != nil {
+			return newResolveError(err, node)
 		}
 	}
 
 	return nil
 }
 
-// resolveNode resolves a single node.
-func (c *Container) resolveNode(node *types.Node) error {
+// resolveParallel resolves the container's nodes concurrently, resolving
+// each node once all of its dependencies have been resolved.
+// Since a node only ever reads the values of the nodes it depends on,
+// the values received by each constructor are the same as they would be
+// in a sequential resolution.
+func (c *Container) resolveParallel(ctx context.Context) error {
+	return c.graph.Walk(c.workers, func(node *types.Node) error {
+		if err := c.resolveNode(ctx, node); err != nil {
+			return newResolveError(err, node)
+		}
+		return nil
+	})
+}
+
+// unresolveChanged marks the nodes which are new, or whose dependencies
+// have different providers or providers which are resolved again, as
+// not resolved, so that the next resolution re-executes them. The
+// container must be built.
+func (c *Container) unresolveChanged() {
+	if c.inputs == nil {
+		c.inputs = make(map[*types.Node]map[*types.Node]struct{})
+	}
+
+	changed := make(map[*types.Node]bool)
+	for _, node := range c.sortedNodes {
+		inputs := c.inputsOf(node)
+		previous, built := c.inputs[node]
+		c.inputs[node] = inputs
+
+		changed[node] = !built || !maps.Equal(previous, inputs)
+		for provider := range inputs {
+			changed[node] = changed[node] || changed[provider]
+		}
+		if changed[node] {
+			node.Unresolve()
+		}
+	}
+}
+
+// inputsOf returns the providers of the node's dependencies which are
+// resolved before it, excluding the values produced by factories.
+func (c *Container) inputsOf(node *types.Node) map[*types.Node]struct{} {
+	inputs := make(map[*types.Node]struct{})
+	for i, dep := range node.Dependencies() {
+		if _, ok := c.producedBy(dep.Type); ok || c.isAssistedArg(node, i) ||
+			reflect.IsContext(dep.Type) {
+			continue
+		}
+		providers, _ := c.providersOfDep(dep)
+		for _, provider := range providers {
+			inputs[provider] = struct{}{}
+		}
+	}
+	return inputs
+}
+
+// resolveNode resolves a single node, unless it has already been
+// resolved, for instance on demand by a factory. The node is skipped if
+// the context is already done.
+func (c *Container) resolveNode(ctx context.Context, node *types.Node) error {
+	// Transient nodes are constructed whenever they are depended on, and
+	// assisted nodes whenever their factories are called.
+	if node.Transient() || c.isAssisted(node) {
+		return nil
+	}
+
+	// A node which is still being constructed cannot be waited on, and
+	// can only be required again through a lazy dependency.
+	if err := checkCycle(ctx, node); err != nil {
+		return err
+	}
+
+	// The same goes for a node being constructed by another chain, such
+	// as on another worker, which waits for a node held by this chain.
+	ch := chainFrom(ctx)
+	if ch == nil {
+		ctx, ch = newChain(ctx)
+		defer ch.done.Store(true)
+	}
+	if err := c.waits.wait(ch, node); err != nil {
+		return err
+	}
+
+	held := false
+	err := node.ResolveOnce(func() error {
+		c.waits.hold(ch, node)
+		held = true
+		defer c.waits.release(node)
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		_, err := c.construct(ctx, node, nil)
+		return err
+	})
+	if !held {
+		c.waits.stop(ch, node)
+	}
+	return err
+}
+
+// construct executes the node's constructor with its dependencies.
+// Singleton nodes store their values in the node itself and are returned
+// as is, while transient and assisted nodes return a new instance of the
+// node which holds the values. The dependencies at the indices of args
+// are passed those values rather than resolved from the registry.
+func (c *Container) construct(
+	ctx context.Context, node *types.Node, args map[int]reflect.Value,
+) (*types.Node, error) {
+	if err := checkCycle(ctx, node); err != nil {
+		return nil, err
+	}
+	ctx, f := pushFrame(ctx, node)
+	defer f.done.Store(true)
+
+	// Factories may outlive the constructor, so they are bound to the
+	// context from before its timeout, which is cancelled once it returns.
+	factoryCtx := ctx
+	if timeout := node.Timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	dependencies := node.Dependencies()
 	values := make([]any, 0)
-	for _, dep := range dependencies {
-		// Get all the providers for each dependency.
-		providers, err := c.registry.Lookup(dep.Type, dep.IsVariadic)
-		if err != nil {
-			return err
+	for i, dep := range dependencies {
+		if arg, ok := args[i]; ok {
+			values = append(values, arg.Interface())
+			continue
 		}
 
-		// If the dependency is a variadic argument and there are no
-		// providers, we can skip the dependency.
-		if len(providers) == 0 && dep.IsVariadic {
+		// Contexts are not provided by the registry, they are the
+		// context the node is being resolved with.
+		if reflect.IsContext(dep.Type) {
+			values = append(values, ctx)
 			continue
 		}
 
-		var value reflect.Value
+		if fnType, ok := assistedFactoryOf(dep.Type); ok {
+			values = append(values, c.newAssistedFactory(factoryCtx, dep.Type, fnType).Interface())
+			continue
+		}
 
-		// If the dependency is an array or slice, create a slice of the
-		// appropriate size and set the values from the providers.
-		if c.inferLists && (dep.IsArray || dep.IsSlice) {
-			// Validate that the number of providers matches the expected size.
-			if dep.IsArray && len(providers) != dep.ArraySize {
-				return errors.Newf(
-					expectedArraySizeErrMsg, dep.ArraySize, len(providers),
-				)
-			}
+		// Factories are created rather than looked up, and construct
+		// their value when they are called.
+		if t, ok := c.factoryOf(dep.Type); ok {
+			values = append(values, c.newFactory(factoryCtx, dep, t).Interface())
+			continue
+		}
 
-			value, err = newSliceOfDep(dep, providers, c.inferInterfaces)
-			if err != nil {
-				return err
-			}
-		} else if len(providers) != 1 {
-			// If the dependency is not a list or slice and not variadic and
-			// there is not exactly one provider, return an error.
-			return errors.Newf(expected1ProviderErrMsg, len(providers))
-		} else {
-			// Otherwise, get the value from the provider. At this point, if the
-			// dependency is a list or a slice, it must be provided exactly as is.
-			value, err = providers[0].ValueOf(dep.Type, false, c.inferInterfaces)
-			if err != nil {
-				return err
-			}
+		value, ok, err := c.valueOfDep(ctx, dep)
+		if err != nil {
+			return nil, err
+		} else if !ok && dep.Optional {
+			// Optional dependencies without providers are passed as
+			// the zero value of their type.
+			values = append(values, nil)
+			continue
+		} else if !ok {
+			continue
 		}
 
 		values = append(values, value.Interface())
 	}
 
-	if err := node.Execute(c.inferInterfaces, values...); err != nil {
+	var err error
+	instance := node
+	if node.Transient() || c.isAssisted(node) {
+		instance, err = node.Instantiate(ctx, c.inferInterfaces, values...)
+	} else {
+		err = node.Execute(ctx, c.inferInterfaces, values...)
+	}
+	if err != nil {
+		var panicErr *reflect.PanicError
+		if errors.As(err, &panicErr) {
+			return nil, &ConstructorError{
+				ID:    node.ID(),
+				Path:  c.dependencyPath(node),
+				Value: panicErr.Value,
+				Stack: panicErr.Stack,
+			}
+		}
+		return nil, err
+	}
+
+	c.registerCleanups(instance)
+	return instance, nil
+}
+
+// instancesOf returns the nodes which hold the values of the given
+// providers. Singleton providers are resolved if they have not been yet,
+// and transient providers are constructed anew.
+func (c *Container) instancesOf(
+	ctx context.Context, providers []*types.Node,
+) ([]*types.Node, error) {
+	instances := make([]*types.Node, len(providers))
+	constructed := make(map[*types.Node]*types.Node, len(providers))
+	for i, provider := range providers {
+		// A provider which returns several values of the dependency's
+		// type is listed once for each value, but is only constructed
+		// once.
+		if instance, ok := constructed[provider]; ok {
+			instances[i] = instance
+			continue
+		}
+
+		if c.isAssisted(provider) {
+			return nil, errors.Newf(assistedProviderErrMsg, provider.ID())
+		}
+
+		var err error
+		instance := provider
+		if provider.Transient() {
+			instance, err = c.construct(ctx, provider, nil)
+		} else {
+			err = c.resolveNode(ctx, provider)
+		}
+		if err != nil {
+			return nil, newResolveError(err, provider)
+		}
+		instances[i] = instance
+		constructed[provider] = instance
+	}
+	return instances, nil
+}
+
+// newResolveError wraps an error from resolving the given node. Errors
+// from nodes generated for the fields of Out structs are reported on the
+// constructor the node was generated from, and are not wrapped again if
+// they already are, such as when that constructor fails.
+func newResolveError(err error, node *types.Node) error {
+	var cErr *containerError
+	if errors.As(err, &cErr) && cErr.resolvingType == node.SourceID() {
 		return err
 	}
+	return newContainerError(err, resolveErrorName, node.SourceID())
+}
 
+// checkCycle returns an error if the given node is already being
+// constructed by the caller.
+func checkCycle(ctx context.Context, node *types.Node) error {
+	if path := cycleTo(ctx, node); path != nil {
+		return errors.Newf(
+			lazyCycleErrMsg,
+			graph.ErrAcyclicConstraintViolation,
+			strings.Join(path, " -> "),
+		)
+	}
 	return nil
 }
 
+// dependencyPath returns the shortest chain of nodes through which a
+// type invoked from the container depends on the given node, from the
+// provider of the invoked type to the node itself. If no invoked type
+// depends on the node, the path only holds the node.
+func (c *Container) dependencyPath(node *types.Node) []string {
+	targets := make(map[*types.Node]bool)
+	for _, root := range c.invokedRoots() {
+		for _, provider := range c.providersReachedBy(root) {
+			targets[provider] = true
+		}
+	}
+
+	// Walk the dependents of the node breadth first, recording the node
+	// each dependent was reached from.
+	next := map[*types.Node]*types.Node{node: nil}
+	queue := []*types.Node{node}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if targets[current] {
+			path := make([]string, 0)
+			for ; current != nil; current = next[current] {
+				path = append(path, current.ID())
+			}
+			return path
+		}
+
+		dependents := slices.Concat(
+			c.graph.Successors(current), c.graph.WeakSuccessors(current),
+		)
+		for _, dependent := range dependents {
+			if _, seen := next[dependent]; !seen {
+				next[dependent] = current
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	return []string{node.ID()}
+}
+
+// valueOfDep returns the value which satisfies the given dependency from
+// the providers currently registered in the container. Transient
+// providers are constructed anew with the given context. It returns false
+// if the dependency is variadic or optional and has no providers, in
+// which case the dependency should be skipped.
+func (c *Container) valueOfDep(
+	ctx context.Context, dep *reflect.Arg,
+) (reflect.Value, bool, error) {
+	// Get all the providers for the dependency.
+	providers, err := c.providersOfDep(dep)
+	if err != nil {
+		return reflect.Value{}, false, err
+	}
+
+	// If the dependency is a variadic or optional argument and there
+	// are no providers, we can skip the dependency.
+	if len(providers) == 0 && (dep.IsVariadic || dep.Optional) {
+		return reflect.Value{}, false, nil
+	}
+
+	// Replace any transient providers with new instances of them.
+	if providers, err = c.instancesOf(ctx, providers); err != nil {
+		return reflect.Value{}, false, err
+	}
+
+	var value reflect.Value
+
+	// If the dependency is an array or slice, create a slice of the
+	// appropriate size and set the values from the providers. Groups
+	// are always slices, whether or not lists are inferred.
+	if (c.inferLists && (dep.IsArray || dep.IsSlice)) || dep.Group != "" {
+		// Validate that the number of providers matches the expected size.
+		if dep.IsArray && len(providers) != dep.ArraySize {
+			return reflect.Value{}, false, errors.Newf(
+				expectedArraySizeErrMsg, dep.ArraySize, len(providers),
+			)
+		}
+
+		value, err = newSliceOfDep(dep, providers, c.inferInterfaces)
+		if err != nil {
+			return reflect.Value{}, false, err
+		}
+	} else if c.isKeyedDep(dep) {
+		// Maps are keyed by the names of the providers of their values.
+		value, err = newMapOfDep(dep, providers, c.inferInterfaces)
+		if err != nil {
+			return reflect.Value{}, false, err
+		}
+	} else if len(providers) != 1 {
+		// If the dependency is not a list or slice and not variadic and
+		// there is not exactly one provider, return an error.
+		return reflect.Value{}, false, errors.Newf(
+			expected1ProviderErrMsg, len(providers),
+		)
+	} else {
+		// Otherwise, get the value from the provider. At this point, if the
+		// dependency is a list or a slice, it must be provided exactly as is.
+		value, err = providers[0].ValueOf(dep.Type, false, c.inferInterfaces)
+		if err != nil {
+			return reflect.Value{}, false, err
+		}
+	}
+
+	return value, true, nil
+}
+
 // newSliceOfDep creates a slice of the given dependency type with the
 // appropriate size and sets the values from the providers.
 // Note: this function is only even called if c.inferLists is true.
@@ -83,11 +396,19 @@ func newSliceOfDep(
 	dep *reflect.Arg, providers []*types.Node, inferInterfaces bool,
 ) (reflect.Value, error) {
 	var values []reflect.Value
+
+	// A provider is listed once for each of its values of the element
+	// type, so each listing takes the provider's next value.
+	next := make(map[*types.Node]int, len(providers))
 	for _, provider := range providers {
-		providerValue, err := provider.ValueOf(dep.Type, true, inferInterfaces)
+		providerValues, err := provider.ValuesOf(dep.Type, true, inferInterfaces)
 		if err != nil {
 			return reflect.Value{}, err
-		} else if dep.Type.Elem() != providerValue.Type() {
+		}
+
+		providerValue := providerValues[next[provider]]
+		next[provider]++
+		if !providerValue.Type().AssignableTo(dep.Type.Elem()) {
 			return reflect.Value{}, errors.Newf(
 				sliceElementTypesMismatchErrMsg,
 				dep.Type,
@@ -98,3 +419,34 @@ func newSliceOfDep(
 	}
 	return reflect.MakeInitializedSlice(dep.Type, values...), nil
 }
+
+// newMapOfDep creates a map of the given dependency type, which sets the
+// value of each provider under the provider's name. Since the providers
+// are looked up by the element type, the values are those the providers
+// would contribute to a slice of the same element type.
+// Note: this function is only even called if c.inferLists is true.
+func newMapOfDep(
+	dep *reflect.Arg, providers []*types.Node, inferInterfaces bool,
+) (reflect.Value, error) {
+	sliceType := reflect.SliceOf(dep.Type.Elem())
+	out := reflect.MakeMap(dep.Type)
+	for _, provider := range providers {
+		key := reflect.ValueOf(provider.Name()).Convert(dep.Type.Key())
+		if out.MapIndex(key).IsValid() {
+			return reflect.Value{}, errors.Newf(
+				duplicateMapKeyErrMsg, provider.Name(), dep.Type,
+			)
+		}
+
+		value, err := newSliceOfDep(
+			reflect.NewArg(sliceType, false),
+			[]*types.Node{provider},
+			inferInterfaces,
+		)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.SetMapIndex(key, value.Index(0))
+	}
+	return out, nil
+}
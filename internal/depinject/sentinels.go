@@ -1,24 +1,44 @@
 package depinject
 
 import (
+	"strconv"
+
 	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/errors"
 	"github.com/skjdfhkskjds/depinject/internal/reflect"
 	"github.com/skjdfhkskjds/depinject/internal/utils"
 )
 
 type (
-	// Note: Embedding the sentinels needs to be at the top level
-	// of the struct, we do not check recursively.
+	// Note: Sentinels can be embedded in a struct which is itself
+	// embedded, and the fields of nested sentinel structs are
+	// flattened into the outer struct.
 	In  struct{ _ sentinel }
 	Out struct{ _ sentinel }
 
 	// internal implementation to use for container resolution
 	sentinel struct{}
+
+	// sentinelStruct is a struct which embeds a sentinel, and which is
+	// passed to or returned from a constructor as a value or a pointer.
+	sentinelStruct struct {
+		*reflect.StructType
+		pointer bool
+	}
+)
+
+// The tags of the fields of sentinel structs.
+const (
+	nameTag     = "name"
+	groupTag    = "group"
+	optionalTag = "optional"
 )
 
 // parseInSentinels parses the sentinel structs in the node's constructor
 // inputs and returns a new set of nodes that execute the constructor of
-// each of the sentinel structs.
+// each of the sentinel structs. The nodes are only registered for the
+// first constructor which takes each struct in a module, and are shared
+// with the constructors which take the same struct after it.
 func parseInSentinels(node *types.Node) ([]*types.Node, error) {
 	sentinelNodes := make([]*types.Node, 0)
 	// Handle the In sentinel structs
@@ -33,17 +53,35 @@ func parseInSentinels(node *types.Node) ([]*types.Node, error) {
 		return nil, err
 	}
 	for _, s := range inSentinelStructs {
-		sentinelNodes = append(
-			sentinelNodes,
-			types.NewNodeFromFunc(s.Constructor()),
-		)
+		// The constructor's arguments are the struct's fields, in order.
+		fn := s.Constructor()
+		if s.pointer {
+			fn = s.PointerConstructor()
+		}
+		for i, arg := range fn.Args {
+			if err = applySentinelTags(arg, s.Fields[i]); err != nil {
+				return nil, err
+			}
+		}
+		if err = checkDistinguishable(s.StructType, fn.Args, false); err != nil {
+			return nil, err
+		}
+		// The node is shared by every constructor which takes the struct,
+		// so unlike the nodes for the fields of Out structs, it has no
+		// origin, and is reported on as is.
+		sentinelNode := types.NewNodeFromFunc(fn)
+		// The fields are resolved from the constructor's module, and the
+		// struct is only needed there, so it is private to the module.
+		sentinelNode.SetModule(node.Module())
+		sentinelNode.SetPrivate(true)
+		sentinelNodes = append(sentinelNodes, sentinelNode)
 	}
 	return sentinelNodes, nil
 }
 
 // parseOutSentinels parses the sentinel structs in the node's constructor
-// outputs and returns a new set of nodes that execute the provider of
-// each of the sentinel structs.
+// outputs and returns a new set of nodes, one for each field of each of
+// the sentinel structs, which provide the field's value.
 func parseOutSentinels(node *types.Node) ([]*types.Node, error) {
 	sentinelNodes := []*types.Node{}
 
@@ -56,40 +94,134 @@ func parseOutSentinels(node *types.Node) ([]*types.Node, error) {
 		return nil, err
 	}
 	for _, s := range outSentinelStructs {
-		sentinelNodes = append(
-			sentinelNodes,
-			types.NewNodeFromFunc(s.Provider()),
-		)
+		outputs := make([]*reflect.Arg, len(s.Fields))
+		for i, field := range s.Fields {
+			outputs[i] = reflect.NewArg(field.Type, false)
+			if err = applySentinelTags(outputs[i], field); err != nil {
+				return nil, err
+			}
+		}
+
+		// Several fields may contribute to the same group, but any
+		// other fields must be told apart.
+		if err = checkDistinguishable(s.StructType, outputs, true); err != nil {
+			return nil, err
+		}
+
+		for i, field := range s.Fields {
+			provider := s.FieldProvider(field)
+			if s.pointer {
+				provider = s.PointerFieldProvider(field)
+			}
+			fieldNode := types.NewNodeFromFunc(provider)
+			fieldNode.SetOrigin(node)
+			// The fields of a transient constructor's struct are read
+			// from a new instance of the struct for every dependent.
+			fieldNode.SetTransient(node.Transient())
+			// The fields of a supplied struct are owned by the caller.
+			fieldNode.SetSupplied(node.Supplied())
+			fieldNode.SetModule(node.Module())
+			fieldNode.SetPrivate(node.Private())
+			if outputs[i].Name != "" {
+				fieldNode.SetName(outputs[i].Name)
+			}
+			fieldNode.SetGroup(outputs[i].Group)
+			sentinelNodes = append(sentinelNodes, fieldNode)
+		}
 	}
 
 	return sentinelNodes, nil
 }
 
-// structsForSentinelByType returns a list of structs that embed the
-// sentinel type. It also filters out the sentinel type as a field from
-// the structs.
+// structsForSentinelByType returns a list of structs, or pointers to
+// structs, that embed the sentinel type. It also filters out the sentinel
+// type as a field from the structs, along with unexported fields, which
+// cannot be set.
 func structsForSentinelByType(
 	sourceTypes []reflect.Type, sentinelType reflect.Type,
-) ([]*reflect.StructType, error) {
-	structs := make([]*reflect.StructType, 0)
+) ([]*sentinelStruct, error) {
+	structs := make([]*sentinelStruct, 0)
 	for _, sourceType := range sourceTypes {
+		pointer := sourceType != nil && sourceType.Kind() == reflect.Ptr
+		if pointer {
+			sourceType = sourceType.Elem()
+		}
 		if embedsSentinel(sourceType, sentinelType) {
 			s, err := reflect.NewStruct(sourceType)
 			if err != nil {
 				return nil, err
 			}
-			// Filter out the sentinel struct as a field.
-			isNotSentinel := func(t reflect.Type) bool {
-				return t != sentinelType
-			}
-			s.Fields = s.Fields.Filter(isNotSentinel)
-			structs = append(structs, s)
+			// Flatten nested sentinel structs, then filter out the
+			// sentinel struct as a field.
+			s.Flatten(func(field *reflect.StructField) bool {
+				return embedsSentinel(field.Type, sentinelType)
+			})
+			s.FilterFields(func(field *reflect.StructField) bool {
+				return field.Type != sentinelType && field.Exported
+			})
+			structs = append(structs, &sentinelStruct{s, pointer})
 		}
 	}
 
 	return structs, nil
 }
 
+// applySentinelTags sets the name, group and optionality of the given
+// argument from the tags of the sentinel struct field it is for:
+//
+//	type Params struct {
+//		depinject.In
+//
+//		Primary  *sql.DB   `name:"primary"`
+//		Replica  *sql.DB   `name:"replica" optional:"true"`
+//		Handlers []Handler `group:"handlers"`
+//	}
+func applySentinelTags(arg *reflect.Arg, field *reflect.StructField) error {
+	arg.Name = field.Tag.Get(nameTag)
+	arg.Group = field.Tag.Get(groupTag)
+	if arg.Name != "" && arg.Group != "" {
+		return errors.Newf(nameAndGroupErrMsg, field.Name)
+	}
+
+	if optional, ok := field.Tag.Lookup(optionalTag); ok {
+		var err error
+		if arg.Optional, err = strconv.ParseBool(optional); err != nil {
+			return errors.Newf(invalidOptionalTagErrMsg, optional, field.Name)
+		}
+	}
+	return nil
+}
+
+// checkDistinguishable returns an error if two of the given arguments,
+// one for each field of the struct, have the same type, name and group,
+// in which case they cannot be told apart. If allowGroups is true,
+// arguments in a group are exempt.
+func checkDistinguishable(
+	s *reflect.StructType, args []*reflect.Arg, allowGroups bool,
+) error {
+	type key struct {
+		t           reflect.Type
+		name, group string
+	}
+
+	seen := make(map[key]int, len(args))
+	for i, arg := range args {
+		if allowGroups && arg.Group != "" {
+			continue
+		}
+
+		k := key{arg.Type, arg.Name, arg.Group}
+		if j, ok := seen[k]; ok {
+			return errors.Newf(
+				indistinguishableFieldsErrMsg,
+				s.Fields[j].Name, s.Fields[i].Name, s.Type, arg.Type,
+			)
+		}
+		seen[k] = i
+	}
+	return nil
+}
+
 // embedsSentinel returns true if the given type embeds the sentinel type.
 // It returns false otherwise, including if the type is nil or exactly
 // the sentinel type itself.
@@ -103,9 +235,14 @@ func embedsSentinel(t reflect.Type, sentinel reflect.Type) bool {
 		return false
 	}
 
-	// Iterate through all fields of the struct
+	// Iterate through all fields of the struct, and the fields of the
+	// structs embedded in it.
 	for i := 0; i < t.NumField(); i++ {
-		if t.Field(i).Type == sentinel {
+		field := t.Field(i)
+		if field.Type == sentinel {
+			return true
+		}
+		if field.Anonymous && embedsSentinel(field.Type, sentinel) {
 			return true
 		}
 	}
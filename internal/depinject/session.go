@@ -0,0 +1,258 @@
+package depinject
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/graph"
+)
+
+// A session is a period during which a goroutine holds the container's
+// lock to resolve values from it. Factories created during a session
+// are bound to it, so that calling them while the session is active
+// does not attempt to acquire the lock again. Once the session is
+// finished, factories acquire the lock themselves.
+type session struct {
+	mu       sync.Mutex
+	finished bool
+
+	// inflight tracks the factory calls made during the session, which
+	// must complete before the session can be finished.
+	inflight sync.WaitGroup
+}
+
+type sessionKey struct{}
+
+// newSession returns a new session, and a context which carries it.
+func newSession(ctx context.Context) (context.Context, *session) {
+	s := &session{}
+	return context.WithValue(ctx, sessionKey{}, s), s
+}
+
+// sessionFrom returns the session carried by the given context, if any.
+func sessionFrom(ctx context.Context) *session {
+	s, _ := ctx.Value(sessionKey{}).(*session)
+	return s
+}
+
+// enter returns true if the session is still active, in which case the
+// caller may use the container without acquiring its lock, and must call
+// leave once it is done.
+func (s *session) enter() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return false
+	}
+	s.inflight.Add(1)
+	return true
+}
+
+// leave marks a call which entered the session as complete.
+func (s *session) leave() {
+	s.inflight.Done()
+}
+
+// finish ends the session, waiting for any calls which entered it to
+// complete. It must be called before the container's lock is released.
+func (s *session) finish() {
+	s.mu.Lock()
+	s.finished = true
+	s.mu.Unlock()
+
+	s.inflight.Wait()
+}
+
+// joinSession returns a context bound to an active session, and a
+// function which must be called once the caller is done with the
+// container. If the session carried by the given context is still
+// active, the container is already locked and is used as is. The same
+// goes for a factory from an earlier session which is called while the
+// container is being resolved under the write lock, such as by one of
+// the constructors being resolved, in which case it joins that
+// resolution's session. Otherwise, a new session is started under the
+// read lock, without the cancellation of the given context.
+func (c *Container) joinSession(ctx context.Context) (context.Context, func()) {
+	if s := sessionFrom(ctx); s.enter() {
+		return ctx, s.leave
+	}
+	if s := c.resolving.Load(); s.enter() {
+		return context.WithValue(ctx, sessionKey{}, s), s.leave
+	}
+
+	c.mu.RLock()
+	ctx, s := newSession(context.WithoutCancel(ctx))
+	return ctx, func() {
+		s.finish()
+		c.mu.RUnlock()
+	}
+}
+
+// A frame records a node whose constructor is being executed, so that
+// cycles through lazy dependencies, which are not part of the graph, can
+// be detected when they are resolved.
+type frame struct {
+	node   *types.Node
+	parent *frame
+	done   atomic.Bool
+}
+
+type frameKey struct{}
+
+// pushFrame returns a context which records that the given node is
+// being constructed, and the frame recording it, which must be marked
+// as done once the constructor returns.
+func pushFrame(ctx context.Context, node *types.Node) (context.Context, *frame) {
+	f := &frame{node: node, parent: frameFrom(ctx)}
+	return context.WithValue(ctx, frameKey{}, f), f
+}
+
+// frameFrom returns the innermost frame carried by the given context,
+// if any.
+func frameFrom(ctx context.Context) *frame {
+	f, _ := ctx.Value(frameKey{}).(*frame)
+	return f
+}
+
+// cycleTo returns the IDs of the nodes which led to constructing the
+// given node again while it is still being constructed, or nil if it
+// is not being constructed.
+func cycleTo(ctx context.Context, node *types.Node) []string {
+	path := []string{node.ID()}
+	for f := frameFrom(ctx); f != nil; f = f.parent {
+		path = append([]string{f.node.ID()}, path...)
+		if f.node == node && !f.done.Load() {
+			return path
+		}
+	}
+	return nil
+}
+
+// A chain is a call to resolveNode which is not made on behalf of a
+// constructor being executed, along with the calls made on behalf of the
+// constructors it executes, which all wait on each other.
+type chain struct {
+	done atomic.Bool
+}
+
+type chainKey struct{}
+
+// newChain returns a new chain, and a context which carries it. The
+// chain must be marked as done once the call which started it returns.
+func newChain(ctx context.Context) (context.Context, *chain) {
+	ch := &chain{}
+	return context.WithValue(ctx, chainKey{}, ch), ch
+}
+
+// chainFrom returns the chain carried by the given context, unless it
+// is done, such as for a factory called after its constructor returned.
+func chainFrom(ctx context.Context) *chain {
+	ch, _ := ctx.Value(chainKey{}).(*chain)
+	if ch == nil || ch.done.Load() {
+		return nil
+	}
+	return ch
+}
+
+// waits records the chain which holds each node while it is resolved,
+// and the nodes each chain waits for, so that chains which would wait
+// on each other in a cycle, and therefore never return, fail instead.
+type waits struct {
+	mu      sync.Mutex
+	holders map[*types.Node]*chain
+	waiting map[*chain]map[*types.Node]int
+}
+
+// wait records that the chain waits for the given node, or returns an
+// error if the chain holding the node waits for a node held by the
+// given chain, directly or through other chains.
+func (w *waits) wait(ch *chain, node *types.Node) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.holders == nil {
+		w.holders = make(map[*types.Node]*chain)
+		w.waiting = make(map[*chain]map[*types.Node]int)
+	}
+	if path := w.cycleTo(ch, node, make(map[*chain]bool)); path != nil {
+		// The node held by the given chain comes first and last.
+		path = append([]string{path[len(path)-1]}, path...)
+		return errors.Newf(
+			lazyCycleErrMsg,
+			graph.ErrAcyclicConstraintViolation,
+			strings.Join(path, " -> "),
+		)
+	}
+
+	if w.waiting[ch] == nil {
+		w.waiting[ch] = make(map[*types.Node]int)
+	}
+	w.waiting[ch][node]++
+	return nil
+}
+
+// cycleTo returns the IDs of the nodes waited for from the given node
+// until one held by the given chain, or nil if the node's holder does
+// not wait for the chain.
+func (w *waits) cycleTo(
+	ch *chain, node *types.Node, visited map[*chain]bool,
+) []string {
+	holder := w.holders[node]
+	if holder == ch {
+		return []string{node.ID()}
+	} else if holder == nil || visited[holder] {
+		return nil
+	}
+
+	visited[holder] = true
+	for next := range w.waiting[holder] {
+		if path := w.cycleTo(ch, next, visited); path != nil {
+			return append([]string{node.ID()}, path...)
+		}
+	}
+	return nil
+}
+
+// hold records that the chain no longer waits for the given node, and
+// holds it instead.
+func (w *waits) hold(ch *chain, node *types.Node) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopLocked(ch, node)
+	w.holders[node] = ch
+}
+
+// stop records that the chain no longer waits for the given node.
+func (w *waits) stop(ch *chain, node *types.Node) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopLocked(ch, node)
+}
+
+func (w *waits) stopLocked(ch *chain, node *types.Node) {
+	if w.waiting[ch][node]--; w.waiting[ch][node] == 0 {
+		delete(w.waiting[ch], node)
+	}
+	if len(w.waiting[ch]) == 0 {
+		delete(w.waiting, ch)
+	}
+}
+
+// release records that the given node is no longer held.
+func (w *waits) release(node *types.Node) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.holders, node)
+}
@@ -0,0 +1,57 @@
+package depinject
+
+import (
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+)
+
+// A structConstructor is passed to Provide in place of a constructor for
+// a struct, or a pointer to a struct, whose fields are its dependencies.
+type structConstructor struct {
+	t reflect.Type
+}
+
+// Struct returns a constructor for the given struct type, or pointer to
+// struct type, which can be passed to Provide. The constructor depends on
+// each of the struct's exported fields, unless the field is tagged with
+// `inject:"-"`. The inject tag of a field can also name the provider the
+// field is resolved from, and mark the field as optional, in the same
+// way as for Populate.
+func Struct(t reflect.Type) any {
+	return &structConstructor{t: t}
+}
+
+// constructor generates the constructor of the struct.
+func (s *structConstructor) constructor() (*reflect.Func, error) {
+	structType := s.t
+	isPointer := structType.Kind() == reflect.Ptr
+	if isPointer {
+		structType = structType.Elem()
+	}
+
+	st, err := reflect.NewStruct(structType)
+	if err != nil {
+		return nil, errors.Join(err, errors.New(s.t.String()))
+	}
+
+	// Unexported fields cannot be set, so they are left as is.
+	st.FilterFields(func(field *reflect.StructField) bool {
+		return field.Exported && field.Tag.Get(injectTag) != skipTag
+	})
+
+	fn := st.Constructor()
+	if isPointer {
+		fn = st.PointerConstructor()
+	}
+
+	// The constructor's arguments are the struct's fields, in order.
+	for i, arg := range fn.Args {
+		if err = applyInjectTag(arg, st.Fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err = checkDistinguishable(st, fn.Args, false); err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
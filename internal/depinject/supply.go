@@ -12,6 +12,9 @@ const supplyErrorName = "supply"
 // that are not created by the container, such as command-line
 // arguments or environment variables.
 func (c *Container) Supply(values ...any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for _, value := range values {
 		if err := c.supply(value); err != nil {
 			return c.interceptError(err)
@@ -30,7 +33,13 @@ func (c *Container) supply(value any) error {
 		reflect.TypeOf(value).String(),
 	)
 
-	if err := c.register(types.NewNodeFromFunc(fn), supplyErrorName); err != nil {
+	if c.sealed {
+		return newContainerError(ErrContainerSealed, supplyErrorName, fn.Name)
+	}
+
+	node := types.NewNodeFromFunc(fn)
+	node.SetSupplied(true)
+	if err := c.register(node, supplyErrorName); err != nil {
 		return newContainerError(err, supplyErrorName, fn.Name)
 	}
 	return nil
@@ -9,7 +9,32 @@ const (
 	// providers are registered for the same type.
 	multipleProvidersErrMsg = "multiple providers registered for type %v"
 
+	// duplicateOutputsErrMsg is the error message for when a node
+	// returns several values of the same type without list inference.
+	duplicateOutputsErrMsg = "constructor %s returns multiple values of type %v, which requires list inference"
+
 	// noProvidersErrMsg is the error message for when no providers
 	// are registered for the given type.
 	noProvidersErrMsg = "no providers registered for type %v"
+
+	// noNamedProvidersErrMsg is the error message for when no providers
+	// are registered for the given type under the given name.
+	noNamedProvidersErrMsg = "no providers registered for type %v named %q"
+
+	// noKeyedProvidersErrMsg is the error message for when no named
+	// providers are registered for the element type of a map.
+	noKeyedProvidersErrMsg = "no named providers registered for type %v"
+
+	// privateProvidersErrMsg is the error message for when the only
+	// providers registered for the given type are private to modules
+	// the dependency is not resolved from.
+	privateProvidersErrMsg = "no providers registered for type %v visible from module %q, but %s is private to module %q"
+
+	// constructorTimeoutErrMsg is the error message for when a
+	// constructor does not return within its timeout.
+	constructorTimeoutErrMsg = "%w: constructor did not return within %s"
+
+	// constructorInterruptedErrMsg is the error message for when the
+	// context is done while a constructor is running.
+	constructorInterruptedErrMsg = "%w: constructor was interrupted"
 )
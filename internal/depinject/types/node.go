@@ -1,6 +1,12 @@
 package types
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/skjdfhkskjds/depinject/internal/errors"
 	"github.com/skjdfhkskjds/depinject/internal/reflect"
 )
@@ -8,8 +14,49 @@ import (
 type Node struct {
 	id string
 
+	// The name the node's values are provided under. Named values are
+	// only injected into arguments which request them by name.
+	name string
+
+	// The group the node's values are contributed to. Grouped values are
+	// only injected into arguments which request the whole group.
+	group string
+
 	// The wrapped function.
 	constructor *reflect.Func
+
+	// The maximum duration the constructor is allowed to run for.
+	// A zero value means the constructor is not bounded.
+	timeout time.Duration
+
+	// Whether the node's values were supplied from outside the container,
+	// in which case the container does not own them.
+	supplied bool
+
+	// Whether the constructor is executed for every dependent, rather
+	// than once for the lifetime of the container.
+	transient bool
+
+	// The module the node was provided in, as a path of module names
+	// separated by slashes, and whether its values are private to it.
+	module  string
+	private bool
+
+	// The profiles the node is active in. A node without profiles is
+	// active in every profile.
+	profiles []string
+
+	// The node whose constructor this node was generated from, for
+	// nodes generated for the fields of Out sentinel structs.
+	origin *Node
+
+	// The function which receives the values of a constructor call which
+	// returned after its context was done.
+	onDiscard func(*Node)
+
+	// Whether the node's values have been resolved, guarded by mu.
+	resolved bool
+	mu       sync.Mutex
 }
 
 func NewNode(constructor any) (*Node, error) {
@@ -35,6 +82,64 @@ func (n *Node) ID() string {
 	return n.id
 }
 
+// SourceID returns the ID of the node this node was generated from, or
+// the node's own ID if it was not generated.
+func (n *Node) SourceID() string {
+	if n.origin != nil {
+		return n.origin.SourceID()
+	}
+	return n.id
+}
+
+// Location returns the file and line the node's constructor is declared
+// at, or that of the constructor the node was generated from.
+func (n *Node) Location() string {
+	if n.origin != nil {
+		return n.origin.Location()
+	}
+	return n.constructor.Location
+}
+
+func (n *Node) Name() string {
+	return n.name
+}
+
+func (n *Node) Group() string {
+	return n.group
+}
+
+func (n *Node) Timeout() time.Duration {
+	return n.timeout
+}
+
+func (n *Node) Transient() bool {
+	return n.transient
+}
+
+func (n *Node) Supplied() bool {
+	return n.supplied
+}
+
+func (n *Node) Module() string {
+	return n.module
+}
+
+func (n *Node) Private() bool {
+	return n.private
+}
+
+// VisibleFrom returns true if the node's values can be resolved from the
+// given module, which is the case unless the node is private to a module
+// which is neither the given module nor one of its parents.
+func (n *Node) VisibleFrom(module string) bool {
+	return !n.private || n.module == "" || module == n.module ||
+		strings.HasPrefix(module, n.module+"/")
+}
+
+func (n *Node) Profiles() []string {
+	return n.profiles
+}
+
 func (n *Node) Dependencies() []*reflect.Arg {
 	return n.constructor.Args
 }
@@ -52,57 +157,310 @@ func (n *Node) Dependencies() []*reflect.Arg {
 func (n *Node) ValueOf(
 	t reflect.Type, matchElement, inferInterfaces bool,
 ) (reflect.Value, error) {
-	if value, ok := n.constructor.Ret[t]; ok && value.IsValid() {
-		return value, nil
+	values, err := n.ValuesOf(t, matchElement, inferInterfaces)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return values[0], nil
+}
+
+// ValuesOf returns every value of the constructor that matches the given
+// type, following the same order and requirements as ValueOf. Values
+// which match in the same way are returned in the order the constructor
+// returns them.
+func (n *Node) ValuesOf(
+	t reflect.Type, matchElement, inferInterfaces bool,
+) ([]reflect.Value, error) {
+	matchers := []func(reflect.Type) bool{
+		func(returnType reflect.Type) bool { return returnType == t },
 	}
 	if matchElement {
-		if value, ok := n.constructor.Ret[t.Elem()]; ok && value.IsValid() {
-			return value, nil
-		} else if ok {
-			return reflect.Value{}, errors.Newf(
-				noValueForTypeErrMsg, t.Elem(), n.ID(),
-			)
+		matchers = append(matchers, func(returnType reflect.Type) bool {
+			return returnType == t.Elem()
+		})
+	}
+	if inferInterfaces {
+		matchers = append(matchers, func(returnType reflect.Type) bool {
+			return returnType.AssignableTo(t)
+		})
+		if matchElement {
+			matchers = append(matchers, func(returnType reflect.Type) bool {
+				return returnType.AssignableTo(t.Elem())
+			})
+		}
+	}
+
+	values := make([]reflect.Value, 0)
+	matched := make([]bool, len(n.constructor.Returns))
+	for _, matches := range matchers {
+		for i, returnType := range n.constructor.Returns {
+			if matched[i] || !matches(returnType) {
+				continue
+			}
+			if !n.constructor.Ret[i].IsValid() {
+				return nil, errors.Newf(noValueForTypeErrMsg, returnType, n.ID())
+			}
+			matched[i] = true
+			values = append(values, n.constructor.Ret[i])
 		}
 	}
 
-	if !inferInterfaces {
-		return reflect.Value{}, errors.Newf(
-			noValueForTypeErrMsg, t, n.ID(),
-		)
+	if len(values) == 0 {
+		return nil, errors.Newf(noValueForTypeErrMsg, t, n.ID())
 	}
+	return values, nil
+}
 
-	// If we are inferring interfaces, we search for the first type
-	// that is assignable to the requested type.
-	for returnType, value := range n.constructor.Ret {
-		if returnType.AssignableTo(t) && value.IsValid() {
-			return value, nil
+// Values returns the values returned by the constructor's last execution.
+func (n *Node) Values() []reflect.Value {
+	values := make([]reflect.Value, 0, len(n.constructor.Ret))
+	for _, value := range n.constructor.Ret {
+		if value.IsValid() {
+			values = append(values, value)
 		}
-		if matchElement && returnType.AssignableTo(t.Elem()) && value.IsValid() {
-			return value, nil
-		} else if !value.IsValid() {
-			return reflect.Value{}, errors.Newf(
-				noValueForTypeErrMsg, t.Elem(), n.ID(),
-			)
+	}
+	return values
+}
+
+// Cleanup returns the cleanup function returned by the constructor's
+// last execution, or nil if it did not return one.
+func (n *Node) Cleanup() func() error {
+	cleanup := n.constructor.Cleanup
+	if !cleanup.IsValid() || cleanup.IsNil() {
+		return nil
+	}
+
+	return func() error {
+		res := cleanup.Call(nil)
+		if len(res) == 1 && !res[0].IsNil() {
+			return res[0].Interface().(error)
 		}
+		return nil
 	}
-	return reflect.Value{}, errors.Newf(noValueForTypeErrMsg, t, n.ID())
 }
 
+// Outputs returns the types of the constructor's values, in the order
+// they are declared.
 func (n *Node) Outputs() []reflect.Type {
-	types := make([]reflect.Type, len(n.constructor.Ret))
-	i := 0
-	for t := range n.constructor.Ret {
-		types[i] = t
-		i++
+	return n.constructor.Returns
+}
+
+// ============================================================================
+//                                   Setters
+// ============================================================================
+
+// SetName sets the name the node's values are provided under, which is
+// also included in the node's ID so that the same constructor can be
+// provided under several names.
+func (n *Node) SetName(name string) {
+	n.name = name
+	n.updateID()
+}
+
+// SetOrigin records the node whose constructor this node was generated
+// from.
+func (n *Node) SetOrigin(origin *Node) {
+	n.origin = origin
+}
+
+func (n *Node) SetGroup(group string) {
+	n.group = group
+}
+
+func (n *Node) SetTimeout(timeout time.Duration) {
+	n.timeout = timeout
+}
+
+func (n *Node) SetTransient(transient bool) {
+	n.transient = transient
+}
+
+// SetModule sets the module the node was provided in, which its
+// dependencies are resolved from. The module is also included in the
+// node's ID so that the same constructor can be provided in several
+// modules.
+func (n *Node) SetModule(module string) {
+	n.module = module
+	for _, arg := range n.constructor.Args {
+		arg.Scope = module
 	}
-	return types
+	n.updateID()
+}
+
+// updateID sets the node's ID from its constructor's name, along with
+// the name and the module it is provided under, if any.
+func (n *Node) updateID() {
+	n.id = n.constructor.Name
+	if n.name != "" {
+		n.id += fmt.Sprintf("[name=%q]", n.name)
+	}
+	if n.module != "" {
+		n.id += fmt.Sprintf("[module=%q]", n.module)
+	}
+}
+
+func (n *Node) SetPrivate(private bool) {
+	n.private = private
+}
+
+// AddProfiles adds to the profiles the node is active in.
+func (n *Node) AddProfiles(profiles ...string) {
+	n.profiles = append(n.profiles, profiles...)
+}
+
+func (n *Node) SetSupplied(supplied bool) {
+	n.supplied = supplied
+}
+
+func (n *Node) SetPanicPassthrough(passthrough bool) {
+	n.constructor.PanicPassthrough = passthrough
+}
+
+// SetDiscardHandler sets the function which receives an instance of the
+// node holding the values of a constructor call which returned after its
+// context was done, and which are otherwise lost.
+func (n *Node) SetDiscardHandler(onDiscard func(*Node)) {
+	n.onDiscard = onDiscard
 }
 
 // ============================================================================
 //                                    Misc
 // ============================================================================
 
-// Execute calls the constructor with the given arguments.
-func (n *Node) Execute(inferInterfaces bool, args ...any) error {
-	return n.constructor.Call(inferInterfaces, args...)
+// Execute calls the constructor with the given arguments and stores its
+// values in the node.
+// If the context is done before the constructor returns, Execute returns
+// an error and the constructor's return values are discarded, as
+// described by exec.
+func (n *Node) Execute(
+	ctx context.Context, inferInterfaces bool, args ...any,
+) error {
+	values, err := n.exec(ctx, inferInterfaces, args...)
+	if err != nil {
+		return err
+	}
+
+	n.constructor.SetReturns(values)
+	return nil
+}
+
+// Instantiate calls the constructor with the given arguments and returns
+// a new instance of the node which holds its values, leaving the values
+// stored in n untouched.
+func (n *Node) Instantiate(
+	ctx context.Context, inferInterfaces bool, args ...any,
+) (*Node, error) {
+	values, err := n.exec(ctx, inferInterfaces, args...)
+	if err != nil {
+		return nil, err
+	}
+	return n.newInstance(values), nil
+}
+
+// newInstance returns a new instance of the node which holds the given
+// values, along with the node's metadata.
+func (n *Node) newInstance(values []reflect.Value) *Node {
+	instance := &Node{
+		id:          n.id,
+		name:        n.name,
+		group:       n.group,
+		constructor: n.constructor.Clone(),
+		timeout:     n.timeout,
+		supplied:    n.supplied,
+		transient:   n.transient,
+		module:      n.module,
+		private:     n.private,
+		profiles:    n.profiles,
+		origin:      n.origin,
+		onDiscard:   n.onDiscard,
+		resolved:    true,
+	}
+	instance.constructor.SetReturns(values)
+	return instance
+}
+
+// ResolveOnce calls resolve if the node has not yet been resolved, and
+// marks the node as resolved if it succeeds. Concurrent calls wait for
+// the node to be resolved, rather than resolving it again.
+func (n *Node) ResolveOnce(resolve func() error) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.resolved {
+		return nil
+	}
+	if err := resolve(); err != nil {
+		return err
+	}
+	n.resolved = true
+	return nil
+}
+
+// Unresolve marks the node as not resolved, so that it is resolved again
+// the next time it is needed.
+func (n *Node) Unresolve() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.resolved = false
+}
+
+// exec calls the constructor with the given arguments and returns its
+// values, or an error if the context is done before it returns.
+// Unless the context has a deadline, the constructor is called directly,
+// and its values are discarded if the context is done by the time it
+// returns. Otherwise, the caller stops waiting once the deadline passes,
+// but the constructor keeps running in the background until it returns.
+// Either way, the values of a constructor which returns too late are
+// passed to the node's discard handler, so that they can be cleaned up.
+func (n *Node) exec(
+	ctx context.Context, inferInterfaces bool, args ...any,
+) ([]reflect.Value, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		values, err := n.constructor.Exec(inferInterfaces, args...)
+		if err == nil && ctx.Err() != nil {
+			n.discard(values)
+			return nil, n.doneErr(ctx)
+		}
+		return values, err
+	}
+
+	type result struct {
+		values []reflect.Value
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		values, err := n.constructor.Exec(inferInterfaces, args...)
+		done <- result{values: values, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.values, res.err
+	case <-ctx.Done():
+		go func() {
+			if res := <-done; res.err == nil {
+				n.discard(res.values)
+			}
+		}()
+		return nil, n.doneErr(ctx)
+	}
+}
+
+// doneErr returns the error for a constructor which did not return
+// before the given context was done.
+func (n *Node) doneErr(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && n.timeout > 0 {
+		return errors.Newf(constructorTimeoutErrMsg, ctx.Err(), n.timeout)
+	}
+	return errors.Newf(constructorInterruptedErrMsg, ctx.Err())
+}
+
+// discard passes a new instance of the node which holds the given values
+// to the node's discard handler, if it has one.
+func (n *Node) discard(values []reflect.Value) {
+	if n.onDiscard != nil {
+		n.onDiscard(n.newInstance(values))
+	}
 }
@@ -30,13 +30,26 @@ func NewRegistry(inferLists, inferInterfaces bool) *Registry {
 // Contract:
 //   - if inferLists is true, the registry will permit multiple
 //     providers being registered for the same type.
+//   - if inferLists is false, a node may not return several values of the
+//     same type. Otherwise, the node is registered once for each value.
 func (r *Registry) Register(node *Node) error {
+	if !r.inferLists {
+		seen := make(map[reflect.Type]bool)
+		for _, t := range node.Outputs() {
+			if seen[t] && !reflect.IsError(t) {
+				return errors.Newf(duplicateOutputsErrMsg, node.ID(), t)
+			}
+			seen[t] = true
+		}
+	}
+
 	for _, t := range node.Outputs() {
 		// Skip errors, they are handled separately
 		if reflect.IsError(t) {
 			continue
 		}
-		if _, exists := r.providers[t]; exists && !r.inferLists {
+		if _, exists := r.providers[t]; exists && !r.inferLists &&
+			node.Group() == "" && r.hasConflictingProvider(t, node) {
 			return errors.Newf(multipleProvidersErrMsg, t)
 		} else if !exists {
 			r.providers[t] = make([]*Node, 0)
@@ -46,25 +59,151 @@ func (r *Registry) Register(node *Node) error {
 	return nil
 }
 
-// Lookup returns all the nodes which provide the given type.
+// Lookup returns all the unnamed nodes which provide the given type,
+// and which are visible outside of any module.
 // Contract:
 //   - if inferInterfaces is true, this node will be registered as
 //     a provider for ALL registered types which are assignable by
 //     an output of this node.
 func (r *Registry) Lookup(requested reflect.Type, optional bool) ([]*Node, error) {
+	return r.LookupNamed(requested, "", "", optional)
+}
+
+// LookupNamed returns all the nodes which provide the given type under
+// the given name, and which are visible from the given module, following
+// the same contract as Lookup. Private providers shadow the providers of
+// the modules above theirs.
+func (r *Registry) LookupNamed(
+	requested reflect.Type, name, module string, optional bool,
+) ([]*Node, error) {
 	allProviders := make([]*Node, 0)
+	var hidden *Node
 	for _, t := range r.allMatchingTypes(requested) {
-		providers, ok := r.providers[t]
-		if ok {
-			allProviders = append(allProviders, providers...)
+		for _, provider := range r.providers[t] {
+			if provider.Name() != name || provider.Group() != "" {
+				continue
+			} else if !provider.VisibleFrom(module) {
+				hidden = provider
+				continue
+			}
+			allProviders = append(allProviders, provider)
 		}
 	}
+	allProviders = nearestProviders(allProviders)
+
 	if !optional && len(allProviders) == 0 {
+		if hidden != nil {
+			return nil, errors.Newf(
+				privateProvidersErrMsg, requested, module,
+				hidden.ID(), hidden.Module(),
+			)
+		}
+		if name != "" {
+			return nil, errors.Newf(noNamedProvidersErrMsg, requested, name)
+		}
 		return nil, errors.Newf(noProvidersErrMsg, requested)
 	}
 	return allProviders, nil
 }
 
+// LookupKeyed returns all the named nodes which provide the given type,
+// and which are visible from the given module, such that each node's
+// values are keyed by its name. Private providers shadow the providers
+// of the same name in the modules above theirs.
+func (r *Registry) LookupKeyed(
+	requested reflect.Type, module string, optional bool,
+) ([]*Node, error) {
+	byName := make(map[string][]*Node)
+	names := make([]string, 0)
+	for _, t := range r.allMatchingTypes(requested) {
+		for _, provider := range r.providers[t] {
+			if provider.Name() == "" || provider.Group() != "" ||
+				!provider.VisibleFrom(module) {
+				continue
+			}
+			if _, ok := byName[provider.Name()]; !ok {
+				names = append(names, provider.Name())
+			}
+			byName[provider.Name()] = append(byName[provider.Name()], provider)
+		}
+	}
+
+	allProviders := make([]*Node, 0)
+	for _, name := range names {
+		allProviders = append(allProviders, nearestProviders(byName[name])...)
+	}
+	if !optional && len(allProviders) == 0 {
+		return nil, errors.Newf(noKeyedProvidersErrMsg, requested)
+	}
+	return allProviders, nil
+}
+
+// LookupGroup returns all the nodes which contribute a value assignable
+// to the given element type to the given group. Unlike other lookups,
+// a group may be empty.
+func (r *Registry) LookupGroup(
+	elem reflect.Type, group, module string,
+) []*Node {
+	allProviders := make([]*Node, 0)
+	for t, providers := range r.providers {
+		if t != elem && !(r.inferInterfaces && t.AssignableTo(elem)) {
+			continue
+		}
+		for _, provider := range providers {
+			if provider.Group() == group && provider.VisibleFrom(module) {
+				allProviders = append(allProviders, provider)
+			}
+		}
+	}
+	return allProviders
+}
+
+// hasConflictingProvider returns true if another node provides the given
+// type under the same name as the node, and is visible from exactly the
+// same modules, in which case they cannot be told apart.
+func (r *Registry) hasConflictingProvider(t reflect.Type, node *Node) bool {
+	for _, provider := range r.providers[t] {
+		if provider.Name() == node.Name() && provider.Group() == "" &&
+			scopeOf(provider) == scopeOf(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeOf returns the module the node is private to, or an empty string
+// if it is visible from every module.
+func scopeOf(node *Node) string {
+	if node.Private() {
+		return node.Module()
+	}
+	return ""
+}
+
+// nearestProviders returns the providers which are private to the
+// innermost module, if any of them are private, since they shadow the
+// providers of the modules above theirs. Otherwise, the providers are
+// returned as is.
+func nearestProviders(providers []*Node) []*Node {
+	nearest := ""
+	for _, provider := range providers {
+		if len(scopeOf(provider)) > len(nearest) {
+			nearest = scopeOf(provider)
+		}
+	}
+	if nearest == "" {
+		return providers
+	}
+
+	shadowing := make([]*Node, 0, len(providers))
+	for _, provider := range providers {
+		if scopeOf(provider) == nearest {
+			shadowing = append(shadowing, provider)
+		}
+	}
+	return shadowing
+}
+
 func (r *Registry) Dump() string {
 	var dump strings.Builder
 	for t, nodes := range r.providers {
@@ -91,18 +230,16 @@ func (r *Registry) allMatchingTypes(t reflect.Type) []reflect.Type {
 	for existingType := range r.providers {
 		if t == existingType {
 			continue
-		} else if internalType != nil && internalType == existingType {
-			types = append(types, existingType)
 		}
 
-		// Only check assignability if we are inferring interfaces.
-		if !r.inferInterfaces {
-			continue
-		}
-		if existingType.AssignableTo(t) {
+		// Each type is only matched once, even if it is both the element
+		// type and assignable to it, since its providers are already
+		// listed once for each of their values of the type.
+		if internalType != nil && internalType == existingType {
 			types = append(types, existingType)
-		}
-		if internalType != nil && existingType.AssignableTo(internalType) {
+		} else if r.inferInterfaces && (existingType.AssignableTo(t) ||
+			(internalType != nil && existingType.AssignableTo(internalType))) {
+			// Only check assignability if we are inferring interfaces.
 			types = append(types, existingType)
 		}
 	}
@@ -0,0 +1,165 @@
+package depinject
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/skjdfhkskjds/depinject/internal/depinject/types"
+	"github.com/skjdfhkskjds/depinject/internal/errors"
+	"github.com/skjdfhkskjds/depinject/internal/reflect"
+)
+
+// Unused returns the providers which none of the given types depend on,
+// directly or indirectly, along with the types invoked or populated
+// from the container so far. The dependencies of lazy dependencies and
+// factories are included, even if they have not been called.
+func (c *Container) Unused(roots ...reflect.Type) ([]ProviderInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	assisted := c.assisted
+	if !c.invokable {
+		var err error
+		if assisted, err = c.assistedProviders(); err != nil {
+			return nil, err
+		}
+	}
+
+	deps := c.invokedRoots()
+	for _, root := range roots {
+		deps = append(deps, reflect.NewArg(root, false))
+	}
+	unused := c.unusedProviders(deps, assisted)
+
+	infos := make([]ProviderInfo, len(unused))
+	for i, node := range unused {
+		infos[i] = newProviderInfo(node)
+	}
+	return infos, nil
+}
+
+// addRoots records the given dependencies as invoked from the container,
+// unless they already have been.
+func (c *Container) addRoots(roots []*reflect.Arg) {
+	c.rootsMu.Lock()
+	defer c.rootsMu.Unlock()
+
+	for _, root := range roots {
+		if !slices.ContainsFunc(c.roots, func(dep *reflect.Arg) bool {
+			return dep.Type == root.Type && dep.Name == root.Name
+		}) {
+			c.roots = append(c.roots, root)
+		}
+	}
+}
+
+// invokedRoots returns the dependencies invoked from the container so
+// far.
+func (c *Container) invokedRoots() []*reflect.Arg {
+	c.rootsMu.Lock()
+	defer c.rootsMu.Unlock()
+
+	return slices.Clone(c.roots)
+}
+
+// CheckUnused returns an error wrapping ErrUnusedProviders if a provider
+// is not used, directly or indirectly, by any of the given types. Unlike
+// Unused, the types invoked or populated from the container so far are
+// not taken into account.
+func (c *Container) CheckUnused(roots ...reflect.Type) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	assisted := c.assisted
+	if !c.invokable {
+		var err error
+		if assisted, err = c.assistedProviders(); err != nil {
+			return err
+		}
+	}
+	return c.checkUnused(roots, assisted, introspectErrorName)
+}
+
+// checkUnused returns an error if a provider is not used by any of the
+// given types, given the providers constructed by assisted factories.
+// The error is reported on the first unused provider, and lists all of
+// them.
+func (c *Container) checkUnused(
+	roots []reflect.Type,
+	assisted map[*types.Node]map[int]struct{},
+	sourceName string,
+) error {
+	deps := make([]*reflect.Arg, len(roots))
+	for i, root := range roots {
+		deps[i] = reflect.NewArg(root, false)
+	}
+	unused := c.unusedProviders(deps, assisted)
+	if len(unused) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(unused))
+	for i, node := range unused {
+		ids[i] = node.ID()
+	}
+	return newContainerError(
+		errors.Newf(
+			unusedProvidersErrMsg, ErrUnusedProviders, strings.Join(ids, ", "),
+		),
+		sourceName, unused[0].ID(),
+	)
+}
+
+// unusedProviders returns the nodes which are not reachable from the
+// given dependencies, in the order they were registered, given the
+// providers constructed by assisted factories.
+func (c *Container) unusedProviders(
+	roots []*reflect.Arg, assisted map[*types.Node]map[int]struct{},
+) []*types.Node {
+	used := make(map[*types.Node]bool)
+	queue := make([]*types.Node, 0)
+	visit := func(providers []*types.Node) {
+		for _, provider := range providers {
+			if !used[provider] {
+				used[provider] = true
+				queue = append(queue, provider)
+			}
+		}
+	}
+
+	for _, root := range roots {
+		visit(c.providersReachedBy(root))
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for i, dep := range node.Dependencies() {
+			if _, ok := assisted[node][i]; !ok {
+				visit(c.providersReachedBy(dep))
+			}
+		}
+	}
+
+	unused := make([]*types.Node, 0)
+	for _, node := range c.graph.Vertices() {
+		if !used[node] {
+			unused = append(unused, node)
+		}
+	}
+	return unused
+}
+
+// providersReachedBy returns the providers the given dependency is
+// resolved from, which are the providers of the value produced by a
+// factory dependency.
+func (c *Container) providersReachedBy(dep *reflect.Arg) []*types.Node {
+	if reflect.IsContext(dep.Type) {
+		return nil
+	}
+	if t, ok := c.producedBy(dep.Type); ok {
+		providers, _ := c.registry.LookupNamed(t, "", dep.Scope, true)
+		return providers
+	}
+	providers, _ := c.providersOfDep(dep)
+	return providers
+}
@@ -9,4 +9,6 @@ var (
 	New  = errors.New
 	Newf = fmt.Errorf
 	Join = errors.Join
+	Is   = errors.Is
+	As   = errors.As
 )
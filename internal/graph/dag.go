@@ -1,6 +1,11 @@
 package graph
 
-import "github.com/skjdfhkskjds/depinject/internal/utils"
+import (
+	"maps"
+	"slices"
+
+	"github.com/skjdfhkskjds/depinject/internal/utils"
+)
 
 type DAG[VertexT Vertex] struct {
 	// vertices is a map of vertex IDs to vertices.
@@ -12,6 +17,10 @@ type DAG[VertexT Vertex] struct {
 	edges    map[string][]VertexT
 	indegree map[string]int
 
+	// weakEdges are edges which are considered when checking for cycles,
+	// but which do not constrain the order of the vertices.
+	weakEdges map[string][]VertexT
+
 	totalVertices int
 
 	enforceUniqueVertices bool
@@ -23,6 +32,7 @@ func NewDAG[VertexT Vertex](enforceUniqueVertices bool) *DAG[VertexT] {
 		vertices:              utils.NewOrderedMap[string, []VertexT](),
 		edges:                 make(map[string][]VertexT),
 		indegree:              make(map[string]int),
+		weakEdges:             make(map[string][]VertexT),
 		enforceUniqueVertices: enforceUniqueVertices,
 	}
 }
@@ -47,24 +57,30 @@ func (g *DAG[VertexT]) AddVertex(v VertexT) error {
 		return ErrVertexAlreadyExists
 	}
 
+	// A vertex which shares its ID with existing vertices shares their
+	// edges as well, so their indegree is kept.
 	vertices, ok := g.vertices.Get(v.ID())
 	if !ok {
 		g.vertices.Set(v.ID(), []VertexT{})
+		g.indegree[v.ID()] = 0
 	}
 
 	g.vertices.Set(v.ID(), append(vertices, v))
-	g.indegree[v.ID()] = 0
 	g.totalVertices++
 	return nil
 }
 
 // AddEdge adds a directed edge from vertex 'from' to vertex 'to'.
-// Returns an error if adding the edge would create a cycle.
+// Returns an error if adding the edge would create a cycle. Adding an
+// edge which already exists has no effect.
 func (g *DAG[VertexT]) AddEdge(from, to VertexT) error {
 	// Ensure both vertices exist
 	if !g.hasVertex(from) || !g.hasVertex(to) {
 		return ErrVertexNotFound
 	}
+	if hasEdge(g.edges, from, to) {
+		return nil
+	}
 
 	// Check if adding the edge would create a cycle
 	if g.hasCycle(from, to) {
@@ -77,16 +93,81 @@ func (g *DAG[VertexT]) AddEdge(from, to VertexT) error {
 	return nil
 }
 
+// AddWeakEdge adds a weak directed edge from vertex 'from' to vertex 'to'.
+// Weak edges are not used to order the vertices, but adding one returns
+// an error if it would create a cycle with the other edges in the DAG.
+// Adding a weak edge which already exists has no effect.
+func (g *DAG[VertexT]) AddWeakEdge(from, to VertexT) error {
+	// Ensure both vertices exist
+	if !g.hasVertex(from) || !g.hasVertex(to) {
+		return ErrVertexNotFound
+	}
+	if hasEdge(g.weakEdges, from, to) {
+		return nil
+	}
+
+	// Check if adding the edge would create a cycle
+	if g.hasCycle(from, to) {
+		return ErrAcyclicConstraintViolation
+	}
+
+	g.weakEdges[from.ID()] = append(g.weakEdges[from.ID()], to)
+	return nil
+}
+
+// Successors returns the vertices which have an edge from the given
+// vertex, in the order the edges were added.
+func (g *DAG[VertexT]) Successors(v VertexT) []VertexT {
+	return g.edges[v.ID()]
+}
+
+// WeakSuccessors returns the vertices which have a weak edge from the
+// given vertex, in the order the edges were added.
+func (g *DAG[VertexT]) WeakSuccessors(v VertexT) []VertexT {
+	return g.weakEdges[v.ID()]
+}
+
+// Predecessors returns the vertices which have an edge, or a weak edge,
+// to the given vertex, in the order the vertices were added.
+func (g *DAG[VertexT]) Predecessors(v VertexT) []VertexT {
+	predecessors := make([]VertexT, 0)
+	for _, id := range g.vertices.Keys() {
+		edges := slices.Concat(g.edges[id], g.weakEdges[id])
+		for _, to := range edges {
+			if to.ID() == v.ID() {
+				verticesForKey, _ := g.vertices.Get(id)
+				predecessors = append(predecessors, verticesForKey...)
+				break
+			}
+		}
+	}
+	return predecessors
+}
+
+// RemoveEdgesTo removes every edge, and every weak edge, to the given
+// vertex.
+func (g *DAG[VertexT]) RemoveEdgesTo(v VertexT) {
+	to := func(w VertexT) bool { return w.ID() == v.ID() }
+	for id, edges := range g.edges {
+		g.edges[id] = slices.DeleteFunc(edges, to)
+	}
+	for id, edges := range g.weakEdges {
+		g.weakEdges[id] = slices.DeleteFunc(edges, to)
+	}
+	g.indegree[v.ID()] = 0
+}
+
 // TopologicalSort performs a topological sort on the DAG and
 // returns a slice of vertices in topologically sorted order.
 func (g *DAG[VertexT]) TopologicalSort() ([]VertexT, error) {
 	// Kahn's algorithm for topological sorting
 	var sorted []VertexT
+	indegree := g.indegrees()
 	queue := []string{}
 
 	// Enqueue vertices with zero indegree
 	for _, vertex := range g.vertices.Keys() {
-		if g.indegree[vertex] == 0 {
+		if indegree[vertex] == 0 {
 			queue = append(queue, vertex)
 		}
 	}
@@ -101,8 +182,8 @@ func (g *DAG[VertexT]) TopologicalSort() ([]VertexT, error) {
 		// For each outgoing edge from 'v', reduce indegree and
 		// enqueue if it becomes zero
 		for _, neighbor := range g.edges[v] {
-			g.indegree[neighbor.ID()]--
-			if g.indegree[neighbor.ID()] == 0 {
+			indegree[neighbor.ID()]--
+			if indegree[neighbor.ID()] == 0 {
 				queue = append(queue, neighbor.ID())
 			}
 		}
@@ -115,6 +196,104 @@ func (g *DAG[VertexT]) TopologicalSort() ([]VertexT, error) {
 	return sorted, nil
 }
 
+// Walk calls fn on every vertex in the DAG, using at most workers
+// goroutines at a time. A vertex is only visited once all of the vertices
+// with edges into it have been visited, so independent vertices may be
+// visited concurrently. Vertices which share an ID are visited together,
+// in the order they were added.
+// If fn returns an error, no further vertices are scheduled and the first
+// error is returned once all in-flight calls have completed.
+func (g *DAG[VertexT]) Walk(workers int, fn func(VertexT) error) error {
+	workers = max(workers, 1)
+
+	type walkResult struct {
+		id  string
+		err error
+	}
+	jobs := make(chan string)
+	results := make(chan walkResult)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for id := range jobs {
+				var err error
+				verticesForKey, _ := g.vertices.Get(id)
+				for _, v := range verticesForKey {
+					if err = fn(v); err != nil {
+						break
+					}
+				}
+				results <- walkResult{id: id, err: err}
+			}
+		}()
+	}
+	defer close(jobs)
+
+	// Start with the vertices which have zero indegree.
+	indegree := g.indegrees()
+	ready := []string{}
+	for _, vertex := range g.vertices.Keys() {
+		if indegree[vertex] == 0 {
+			ready = append(ready, vertex)
+		}
+	}
+
+	var (
+		firstErr error
+		running  int
+		visited  int
+	)
+	handle := func(res walkResult) {
+		running--
+		if firstErr != nil {
+			return
+		} else if res.err != nil {
+			firstErr = res.err
+			return
+		}
+
+		verticesForKey, _ := g.vertices.Get(res.id)
+		visited += len(verticesForKey)
+
+		// For each outgoing edge, reduce indegree and mark the
+		// neighbor as ready if it becomes zero.
+		for _, neighbor := range g.edges[res.id] {
+			indegree[neighbor.ID()]--
+			if indegree[neighbor.ID()] == 0 {
+				ready = append(ready, neighbor.ID())
+			}
+		}
+	}
+
+	for running > 0 || (firstErr == nil && len(ready) > 0) {
+		if firstErr != nil || len(ready) == 0 {
+			handle(<-results)
+			continue
+		}
+
+		select {
+		case jobs <- ready[0]:
+			ready = ready[1:]
+			running++
+		case res := <-results:
+			handle(res)
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	// Check if we could visit all vertices (DAG should have no cycles)
+	if visited != g.totalVertices {
+		return ErrAcyclicConstraintViolation
+	}
+	return nil
+}
+
+// indegrees returns a copy of the indegree of every vertex in the DAG.
+func (g *DAG[VertexT]) indegrees() map[string]int {
+	return maps.Clone(g.indegree)
+}
+
 // Helper function to check if adding an edge would create a cycle using DFS.
 func (g *DAG[VertexT]) hasCycle(from, to VertexT) bool {
 	visited := make(map[string]bool)
@@ -130,10 +309,12 @@ func (g *DAG[VertexT]) detectCycle(
 		return true
 	}
 	visited[v.ID()] = true
-	for _, neighbor := range g.edges[v.ID()] {
-		if !visited[neighbor.ID()] {
-			if g.detectCycle(neighbor, target, visited) {
-				return true
+	for _, edges := range []map[string][]VertexT{g.edges, g.weakEdges} {
+		for _, neighbor := range edges[v.ID()] {
+			if !visited[neighbor.ID()] {
+				if g.detectCycle(neighbor, target, visited) {
+					return true
+				}
 			}
 		}
 	}
@@ -141,6 +322,14 @@ func (g *DAG[VertexT]) detectCycle(
 	return false
 }
 
+// hasEdge returns whether the given edges include an edge from vertex
+// 'from' to vertex 'to'.
+func hasEdge[VertexT Vertex](edges map[string][]VertexT, from, to VertexT) bool {
+	return slices.ContainsFunc(edges[from.ID()], func(v VertexT) bool {
+		return v.ID() == to.ID()
+	})
+}
+
 // hasVertex returns whether the given vertex exists in the DAG.
 func (g *DAG[VertexT]) hasVertex(v VertexT) bool {
 	_, exists := g.vertices.Get(v.ID())
@@ -1,7 +1,10 @@
 package graph_test
 
 import (
+	"errors"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/skjdfhkskjds/depinject/internal/graph"
@@ -68,6 +71,62 @@ func TestDAG(t *testing.T) {
 		testutils.RequireErrorIs(t, dag.AddEdge(v2, v1), graph.ErrAcyclicConstraintViolation)
 	})
 
+	t.Run("AddWeakEdge", func(t *testing.T) {
+		dag := graph.NewDAG[testVertex](false)
+		v1 := testVertex{id: "1"}
+		v2 := testVertex{id: "2"}
+		v3 := testVertex{id: "3"}
+
+		testutils.RequireNoError(t, dag.AddVertex(v1))
+		testutils.RequireNoError(t, dag.AddVertex(v2))
+		testutils.RequireNoError(t, dag.AddVertex(v3))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v2))
+		testutils.RequireNoError(t, dag.AddWeakEdge(v2, v3))
+
+		// Weak edges are considered when checking for cycles...
+		testutils.RequireErrorIs(t, dag.AddEdge(v3, v1), graph.ErrAcyclicConstraintViolation)
+		testutils.RequireErrorIs(t, dag.AddWeakEdge(v2, v1), graph.ErrAcyclicConstraintViolation)
+
+		// ...but do not order the vertices.
+		sorted, err := dag.TopologicalSort()
+		testutils.RequireNoError(t, err)
+		testutils.RequireEquals(t, []testVertex{v1, v3, v2}, sorted)
+	})
+
+	t.Run("Successors", func(t *testing.T) {
+		dag := graph.NewDAG[testVertex](false)
+		v1 := testVertex{id: "1"}
+		v2 := testVertex{id: "2"}
+		v3 := testVertex{id: "3"}
+
+		testutils.RequireNoError(t, dag.AddVertex(v1))
+		testutils.RequireNoError(t, dag.AddVertex(v2))
+		testutils.RequireNoError(t, dag.AddVertex(v3))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v3))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v2))
+
+		testutils.RequireEquals(t, []testVertex{v3, v2}, dag.Successors(v1))
+		testutils.RequireEmpty(t, dag.Successors(v2))
+	})
+
+	t.Run("Predecessors", func(t *testing.T) {
+		dag := graph.NewDAG[testVertex](false)
+		v1 := testVertex{id: "1"}
+		v2 := testVertex{id: "2"}
+		v3 := testVertex{id: "3"}
+
+		testutils.RequireNoError(t, dag.AddVertex(v1))
+		testutils.RequireNoError(t, dag.AddVertex(v2))
+		testutils.RequireNoError(t, dag.AddVertex(v3))
+		testutils.RequireNoError(t, dag.AddEdge(v2, v3))
+		testutils.RequireNoError(t, dag.AddWeakEdge(v1, v3))
+
+		// Weak edges are included, in the order the vertices were added.
+		testutils.RequireEquals(t, []testVertex{v1, v2}, dag.Predecessors(v3))
+		testutils.RequireEquals(t, []testVertex{v3}, dag.WeakSuccessors(v1))
+		testutils.RequireEmpty(t, dag.Predecessors(v1))
+	})
+
 	t.Run("TopologicalSort", func(t *testing.T) {
 		dag := graph.NewDAG[testVertex](false)
 		v1 := testVertex{id: "1"}
@@ -85,6 +144,149 @@ func TestDAG(t *testing.T) {
 		testutils.RequireNoError(t, err)
 		testutils.RequireEquals(t, []testVertex{v1, v2, v3}, sorted)
 	})
+
+	t.Run("AddEdge Repeated", func(t *testing.T) {
+		dag := graph.NewDAG[testVertex](false)
+		v1 := testVertex{id: "1"}
+		v2 := testVertex{id: "2"}
+
+		testutils.RequireNoError(t, dag.AddVertex(v1))
+		testutils.RequireNoError(t, dag.AddVertex(v2))
+
+		// Adding an edge again does not add it twice.
+		testutils.RequireNoError(t, dag.AddEdge(v1, v2))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v2))
+		testutils.RequireNoError(t, dag.AddWeakEdge(v1, v2))
+		testutils.RequireNoError(t, dag.AddWeakEdge(v1, v2))
+		testutils.RequireEquals(t, []testVertex{v2}, dag.Successors(v1))
+		testutils.RequireEquals(t, []testVertex{v2}, dag.WeakSuccessors(v1))
+
+		sorted, err := dag.TopologicalSort()
+		testutils.RequireNoError(t, err)
+		testutils.RequireEquals(t, []testVertex{v1, v2}, sorted)
+	})
+
+	t.Run("AddVertex After Edges", func(t *testing.T) {
+		dag := graph.NewDAG[testVertex](false)
+		v1 := testVertex{id: "1"}
+		v2 := testVertex{id: "2"}
+
+		testutils.RequireNoError(t, dag.AddVertex(v2))
+		testutils.RequireNoError(t, dag.AddVertex(v1))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v2))
+
+		// A vertex added with the ID of a vertex which already has edges
+		// keeps being ordered after its predecessors.
+		testutils.RequireNoError(t, dag.AddVertex(v2))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v2))
+
+		sorted, err := dag.TopologicalSort()
+		testutils.RequireNoError(t, err)
+		testutils.RequireEquals(t, []testVertex{v1, v2, v2}, sorted)
+	})
+
+	t.Run("RemoveEdgesTo", func(t *testing.T) {
+		dag := graph.NewDAG[testVertex](false)
+		v1 := testVertex{id: "1"}
+		v2 := testVertex{id: "2"}
+		v3 := testVertex{id: "3"}
+
+		testutils.RequireNoError(t, dag.AddVertex(v1))
+		testutils.RequireNoError(t, dag.AddVertex(v2))
+		testutils.RequireNoError(t, dag.AddVertex(v3))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v2))
+		testutils.RequireNoError(t, dag.AddWeakEdge(v3, v2))
+		testutils.RequireNoError(t, dag.AddEdge(v1, v3))
+
+		// Only the edges to the vertex are removed, so the reverse edges
+		// no longer create a cycle.
+		dag.RemoveEdgesTo(v2)
+		testutils.RequireEmpty(t, dag.Predecessors(v2))
+		testutils.RequireEquals(t, []testVertex{v3}, dag.Successors(v1))
+		testutils.RequireNoError(t, dag.AddEdge(v2, v1))
+		testutils.RequireNoError(t, dag.AddWeakEdge(v2, v3))
+
+		sorted, err := dag.TopologicalSort()
+		testutils.RequireNoError(t, err)
+		testutils.RequireEquals(t, []testVertex{v2, v1, v3}, sorted)
+	})
+}
+
+func TestDAG_Walk(t *testing.T) {
+	// newDiamond returns a graph of the shape 1 -> {2, 3} -> 4.
+	newDiamond := func(t *testing.T) (*graph.DAG[testVertex], []testVertex) {
+		dag := graph.NewDAG[testVertex](false)
+		vertices := []testVertex{{id: "1"}, {id: "2"}, {id: "3"}, {id: "4"}}
+		for _, v := range vertices {
+			testutils.RequireNoError(t, dag.AddVertex(v))
+		}
+		testutils.RequireNoError(t, dag.AddEdge(vertices[0], vertices[1]))
+		testutils.RequireNoError(t, dag.AddEdge(vertices[0], vertices[2]))
+		testutils.RequireNoError(t, dag.AddEdge(vertices[1], vertices[3]))
+		testutils.RequireNoError(t, dag.AddEdge(vertices[2], vertices[3]))
+		return dag, vertices
+	}
+
+	t.Run("visits predecessors first", func(t *testing.T) {
+		dag, vertices := newDiamond(t)
+
+		var mu sync.Mutex
+		visited := make(map[string]int)
+		testutils.RequireNoError(t, dag.Walk(4, func(v testVertex) error {
+			mu.Lock()
+			defer mu.Unlock()
+			visited[v.id] = len(visited)
+			return nil
+		}))
+
+		testutils.RequireLen(t, visited, len(vertices))
+		testutils.RequireTrue(t, visited["1"] < visited["2"])
+		testutils.RequireTrue(t, visited["1"] < visited["3"])
+		testutils.RequireTrue(t, visited["2"] < visited["4"])
+		testutils.RequireTrue(t, visited["3"] < visited["4"])
+
+		// Walking should not consume the graph.
+		sorted, err := dag.TopologicalSort()
+		testutils.RequireNoError(t, err)
+		testutils.RequireLen(t, sorted, len(vertices))
+	})
+
+	t.Run("bounds concurrency", func(t *testing.T) {
+		dag := graph.NewDAG[testVertex](false)
+		for i := 0; i < 20; i++ {
+			testutils.RequireNoError(t, dag.AddVertex(testVertex{id: strconv.Itoa(i)}))
+		}
+
+		var running, maxRunning atomic.Int32
+		testutils.RequireNoError(t, dag.Walk(3, func(testVertex) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			return nil
+		}))
+		testutils.RequireTrue(t, maxRunning.Load() <= 3)
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		dag, _ := newDiamond(t)
+		errWalk := errors.New("walk error")
+
+		var visited atomic.Int32
+		err := dag.Walk(2, func(v testVertex) error {
+			visited.Add(1)
+			if v.id == "1" {
+				return errWalk
+			}
+			return nil
+		})
+		testutils.RequireErrorIs(t, err, errWalk)
+		testutils.RequireEquals(t, int32(1), visited.Load())
+	})
 }
 
 // ----------------------------------------------------------------------------
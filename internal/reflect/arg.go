@@ -14,6 +14,24 @@ type Arg struct {
 	// Whether the argument is an array.
 	IsArray   bool
 	ArraySize int
+
+	// Whether the argument is a map.
+	IsMap bool
+
+	// The name of the provider the argument is resolved from. Unnamed
+	// arguments are only resolved from unnamed providers.
+	Name string
+
+	// The group the argument is resolved from, in which case it is a
+	// slice of the values of every provider in the group.
+	Group string
+
+	// Whether the argument may be left unresolved if it has no providers.
+	Optional bool
+
+	// The module the argument is resolved from, which determines the
+	// private providers that are visible to it.
+	Scope string
 }
 
 func NewArg(t Type, isVariadic bool) *Arg {
@@ -24,6 +42,8 @@ func NewArg(t Type, isVariadic bool) *Arg {
 		arg.ArraySize = t.Len()
 	} else if t.Kind() == reflect.Slice || isVariadic {
 		arg.IsSlice = true
+	} else if t.Kind() == reflect.Map {
+		arg.IsMap = true
 	}
 
 	return arg
@@ -42,8 +62,10 @@ func (a *Arg) IsType(t Type, inferInterfaces bool) bool {
 
 // matchesType returns whether toCheck is exactly expected,
 // or assignable to expected.
+// Since the dynamic type of a value is never an interface, values are
+// always matched against interfaces by assignability.
 func matchesType(toCheck, expected Type, inferInterfaces bool) bool {
-	if inferInterfaces {
+	if inferInterfaces || expected.Kind() == reflect.Interface {
 		return toCheck == expected || toCheck.AssignableTo(expected)
 	}
 	return toCheck == expected
@@ -14,6 +14,7 @@ func TestNewArg(t *testing.T) {
 		wantIsArray   bool
 		wantArraySize int
 		wantIsSlice   bool
+		wantIsMap     bool
 	}{
 		{
 			name:          "fixed length array",
@@ -29,6 +30,14 @@ func TestNewArg(t *testing.T) {
 			wantArraySize: 0,
 			wantIsSlice:   true,
 		},
+		{
+			name:          "map",
+			input:         reflect.TypeOf(map[string]int{}),
+			wantIsArray:   false,
+			wantArraySize: 0,
+			wantIsSlice:   false,
+			wantIsMap:     true,
+		},
 		{
 			name:          "pointer",
 			input:         reflect.TypeOf(&struct{}{}),
@@ -58,6 +67,7 @@ func TestNewArg(t *testing.T) {
 			testutils.RequireEquals(t, arg.IsArray, tt.wantIsArray)
 			testutils.RequireEquals(t, arg.ArraySize, tt.wantArraySize)
 			testutils.RequireEquals(t, arg.IsSlice, tt.wantIsSlice)
+			testutils.RequireEquals(t, arg.IsMap, tt.wantIsMap)
 		})
 	}
 }
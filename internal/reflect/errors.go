@@ -1,6 +1,9 @@
 package reflect
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrNotAFunction is returned when the type is not a function.
@@ -18,3 +21,18 @@ var (
 	// ArgValueIsZeroErrMsg is the error message for an invalid argument value.
 	ArgValueIsZeroErrMsg = "invalid argument value for type %s: got zero value"
 )
+
+var _ error = (*PanicError)(nil)
+
+// PanicError is returned when a function panics during a call.
+type PanicError struct {
+	// Value is the value the function panicked with.
+	Value any
+
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"runtime/debug"
 	"strings"
 
 	"github.com/skjdfhkskjds/depinject/internal/errors"
@@ -26,8 +27,14 @@ type Func struct {
 	// Args is the argument types of the function.
 	Args []*Arg
 
-	// Ret is a mapping of return types to values of the function.
-	Ret map[Type]Value
+	// Returns is the return types of the function, in the order they are
+	// declared, excluding the cleanup function.
+	Returns []Type
+
+	// Ret is the values returned by the last call of the function, in
+	// the same order as Returns. Its values are invalid until the
+	// function is called.
+	Ret []Value
 
 	// IsVariadic is true if the function is variadic.
 	IsVariadic bool
@@ -35,6 +42,23 @@ type Func struct {
 	// HasError is true if the function returns an error.
 	HasError bool
 
+	// HasCleanup is true if the function returns a cleanup function,
+	// that is, its last non-error return value is a func() or a
+	// func() error following at least one other return value.
+	HasCleanup bool
+
+	// Cleanup is the cleanup function returned by the last call.
+	// It is not included in Ret.
+	Cleanup Value
+
+	// Location is the file and line the function is declared at. It is
+	// empty for generated functions.
+	Location string
+
+	// PanicPassthrough is true if panics in the function should not
+	// be recovered when it is called.
+	PanicPassthrough bool
+
 	// fn is the executable Value of the function.
 	fn Value
 }
@@ -56,6 +80,8 @@ func MakeNamedFunc(args []Type, ret []Type, fn func([]Value) []Value, prefix str
 		formatList(generatedFuncNameRetPrefix, ret),
 	)
 	wrappedFunc.Name = name
+	// Generated functions are not declared anywhere.
+	wrappedFunc.Location = ""
 
 	return wrappedFunc
 }
@@ -79,7 +105,8 @@ func WrapFunc(f any) (*Func, error) {
 	fn := &Func{
 		Name:       GetFunctionName(f),
 		Args:       make([]*Arg, funcType.NumIn()),
-		Ret:        make(map[Type]Value, funcType.NumOut()),
+		Returns:    make([]Type, 0, funcType.NumOut()),
+		Location:   getFunctionLocation(f),
 		IsVariadic: funcType.IsVariadic(),
 		fn:         ValueOf(f),
 	}
@@ -95,45 +122,114 @@ func WrapFunc(f any) (*Func, error) {
 		if IsError(funcType.Out(i)) {
 			hasError = true
 		}
-		fn.Ret[funcType.Out(i)] = Value{}
+		if i == cleanupIndex(funcType) {
+			fn.HasCleanup = true
+			continue
+		}
+		fn.Returns = append(fn.Returns, funcType.Out(i))
 	}
+	fn.Ret = make([]Value, len(fn.Returns))
 	fn.HasError = hasError
 
 	return fn, nil
 }
 
-// Call calls the original function with the given arguments.
+// cleanupIndex returns the index of the cleanup function in the return
+// values of the given function type, or -1 if it does not return one.
+func cleanupIndex(funcType Type) int {
+	index := funcType.NumOut() - 1
+	if index >= 0 && IsError(funcType.Out(index)) {
+		index--
+	}
+
+	// A cleanup function must follow at least one other return value.
+	if index < 1 || !IsCleanup(funcType.Out(index)) {
+		return -1
+	}
+	return index
+}
+
+// Call calls the original function with the given arguments and
+// stores its return values in the Func.
 func (f *Func) Call(inferInterfaces bool, args ...any) error {
+	res, err := f.Exec(inferInterfaces, args...)
+	if err != nil {
+		return err
+	}
+
+	f.SetReturns(res)
+	return nil
+}
+
+// Exec calls the original function with the given arguments and returns
+// its return values, without storing them in the Func. It returns an
+// error if the arguments are invalid or if the function returns a
+// non-nil error.
+func (f *Func) Exec(inferInterfaces bool, args ...any) ([]Value, error) {
 	in, err := buildAndValidateCallArgs(
 		args, f.Args, f.IsVariadic, inferInterfaces,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Call the function
-	var res []Value
-	// If the function is variadic and the variadic argument is not
-	// empty, call the function with the variadic argument as a slice.
-	if f.IsVariadic && len(in) >= len(f.Args) {
-		res = f.fn.CallSlice(in)
-	} else {
-		res = f.fn.Call(in)
+	res, err := f.call(in)
+	if err != nil {
+		return nil, err
 	}
 	if len(res) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	if f.HasError && !res[len(res)-1].IsNil() {
-		return res[len(res)-1].Interface().(error)
+		return nil, res[len(res)-1].Interface().(error)
 	}
 
-	// Set the return values in the Func
-	for _, value := range res {
-		f.Ret[value.Type()] = value
+	return res, nil
+}
+
+// call calls the function with the given built arguments. Unless
+// f.PanicPassthrough is set, a panic in the function is recovered and
+// returned as a *PanicError.
+func (f *Func) call(in []Value) (res []Value, err error) {
+	if !f.PanicPassthrough {
+		defer func() {
+			if r := recover(); r != nil {
+				res, err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
 	}
 
-	return nil
+	// If the function is variadic and the variadic argument is not
+	// empty, call the function with the variadic argument as a slice.
+	if f.IsVariadic && len(in) >= len(f.Args) {
+		return f.fn.CallSlice(in), nil
+	}
+	return f.fn.Call(in), nil
+}
+
+// SetReturns sets the return values of the Func to the given values,
+// as returned by Exec.
+func (f *Func) SetReturns(res []Value) {
+	j := 0
+	for i, value := range res {
+		if f.HasCleanup && i == cleanupIndex(f.fn.Type()) {
+			f.Cleanup = value
+			continue
+		}
+		f.Ret[j] = value
+		j++
+	}
+}
+
+// Clone returns a copy of the Func which does not share its return values
+// with the original.
+func (f *Func) Clone() *Func {
+	clone := *f
+	clone.Ret = make([]Value, len(f.Returns))
+	clone.Cleanup = Value{}
+	return &clone
 }
 
 // GetFunctionName returns the name of the function.
@@ -149,6 +245,17 @@ func GetFunctionName(f any) string {
 	return runtime.FuncForPC(ValueOf(f).Pointer()).Name()
 }
 
+// getFunctionLocation returns the file and line the given function is
+// declared at.
+func getFunctionLocation(f any) string {
+	runtimeFunc := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if runtimeFunc == nil {
+		return ""
+	}
+	file, line := runtimeFunc.FileLine(runtimeFunc.Entry())
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
 // buildAndValidateCallArgs validates the arguments against the expected types
 // and returns a list of built arguments.
 func buildAndValidateCallArgs(
@@ -194,6 +301,13 @@ func buildAndValidateCallArgs(
 		}
 
 		argValue := ValueOf(args[i])
+
+		// Optional arguments which were not resolved are passed as the
+		// zero value of their type.
+		if !argValue.IsValid() && e.Optional {
+			callArgValues[i] = reflect.Zero(e.Type)
+			continue
+		}
 		if !argValue.IsValid() {
 			return nil, errors.Newf(ArgValueIsZeroErrMsg, e.String())
 		}
@@ -34,6 +34,14 @@ func divide(a, b int) (int, error) {
 
 func takesAny(v any) {}
 
+func withCleanup() (int, func(), error) {
+	return 1, func() {}, nil
+}
+
+func panics() int {
+	panic("something went wrong")
+}
+
 // TestMakeNamedFunc tests the creation of a new Func instance using MakeNamedFunc.
 func TestMakeNamedFunc(t *testing.T) {
 	tests := []struct {
@@ -45,7 +53,7 @@ func TestMakeNamedFunc(t *testing.T) {
 		wantNumIn    int
 		wantNumOut   int
 		wantInTypes  []*reflect.Arg
-		wantOutTypes map[reflect.Type]reflect.Value
+		wantOutTypes []reflect.Type
 		wantName     string
 	}{
 		{
@@ -59,8 +67,8 @@ func TestMakeNamedFunc(t *testing.T) {
 			wantNumIn:    1,
 			wantNumOut:   1,
 			wantInTypes:  []*reflect.Arg{reflect.NewArg(reflect.TypeOf(0), false)},
-			wantOutTypes: map[reflect.Type]reflect.Value{
-				reflect.TypeOf(1): {},
+			wantOutTypes: []reflect.Type{
+				reflect.TypeOf(1),
 			},
 			wantName: "GeneratedFunc(Args{int}Returns{int})",
 		},
@@ -81,9 +89,9 @@ func TestMakeNamedFunc(t *testing.T) {
 				reflect.NewArg(reflect.TypeOf(0), false),
 				reflect.NewArg(reflect.TypeOf(0), false),
 			},
-			wantOutTypes: map[reflect.Type]reflect.Value{
-				reflect.TypeOf(0):              {},
-				reflect.TypeOf(errors.New("")): {},
+			wantOutTypes: []reflect.Type{
+				reflect.TypeOf(0),
+				reflect.TypeOf(errors.New("")),
 			},
 			wantName: "GeneratedFunc(Args{int, int}Returns{int, *errors.errorString})",
 		},
@@ -96,7 +104,7 @@ func TestMakeNamedFunc(t *testing.T) {
 			wantNumIn:    0,
 			wantNumOut:   0,
 			wantInTypes:  []*reflect.Arg{},
-			wantOutTypes: map[reflect.Type]reflect.Value{},
+			wantOutTypes: []reflect.Type{},
 			wantName:     "GeneratedFunc()",
 		},
 	}
@@ -109,7 +117,7 @@ func TestMakeNamedFunc(t *testing.T) {
 			testutils.RequireEquals(t, tt.wantNumIn, len(fn.Args))
 			testutils.RequireEquals(t, tt.wantNumOut, len(fn.Ret))
 			testutils.RequireEquals(t, tt.wantInTypes, fn.Args)
-			testutils.RequireEquals(t, tt.wantOutTypes, fn.Ret)
+			testutils.RequireEquals(t, tt.wantOutTypes, fn.Returns)
 			testutils.RequireEquals(t, fn.Name, tt.wantName)
 			testutils.RequireEquals(t, fn.HasError, tt.wantHasError)
 		})
@@ -126,7 +134,7 @@ func TestWrapFunc(t *testing.T) {
 		wantNumIn      int
 		wantNumOut     int
 		wantInTypes    []*reflect.Arg
-		wantOutTypes   map[reflect.Type]reflect.Value
+		wantOutTypes   []reflect.Type
 		wantName       string
 		wantIsVariadic bool
 	}{
@@ -138,8 +146,8 @@ func TestWrapFunc(t *testing.T) {
 			wantNumIn:    1,
 			wantNumOut:   1,
 			wantInTypes:  []*reflect.Arg{reflect.NewArg(reflect.TypeOf(0), false)},
-			wantOutTypes: map[reflect.Type]reflect.Value{
-				reflect.TypeOf(0): {},
+			wantOutTypes: []reflect.Type{
+				reflect.TypeOf(0),
 			},
 			wantName:       pkgPath + "add1",
 			wantIsVariadic: false,
@@ -155,9 +163,9 @@ func TestWrapFunc(t *testing.T) {
 				reflect.NewArg(reflect.TypeOf(0), false),
 				reflect.NewArg(reflect.TypeOf(0), false),
 			},
-			wantOutTypes: map[reflect.Type]reflect.Value{
-				reflect.TypeOf(0):                    {},
-				reflect.TypeOf((*error)(nil)).Elem(): {},
+			wantOutTypes: []reflect.Type{
+				reflect.TypeOf(0),
+				reflect.TypeOf((*error)(nil)).Elem(),
 			},
 			wantName:       pkgPath + "divide",
 			wantIsVariadic: false,
@@ -186,6 +194,21 @@ func TestWrapFunc(t *testing.T) {
 			wantName:       "",
 			wantIsVariadic: false,
 		},
+		{
+			name:         "function with cleanup",
+			input:        withCleanup,
+			err:          nil,
+			wantHasError: true,
+			wantNumIn:    0,
+			wantNumOut:   2,
+			wantInTypes:  []*reflect.Arg{},
+			wantOutTypes: []reflect.Type{
+				reflect.TypeOf(0),
+				reflect.TypeOf((*error)(nil)).Elem(),
+			},
+			wantName:       pkgPath + "withCleanup",
+			wantIsVariadic: false,
+		},
 		{
 			name:         "variadic function",
 			input:        addMulti,
@@ -194,8 +217,8 @@ func TestWrapFunc(t *testing.T) {
 			wantNumIn:    1,
 			wantNumOut:   1,
 			wantInTypes:  []*reflect.Arg{reflect.NewArg(reflect.TypeOf([]int{}), true)},
-			wantOutTypes: map[reflect.Type]reflect.Value{
-				reflect.TypeOf(0): {},
+			wantOutTypes: []reflect.Type{
+				reflect.TypeOf(0),
 			},
 			wantName:       pkgPath + "addMulti",
 			wantIsVariadic: true,
@@ -217,8 +240,9 @@ func TestWrapFunc(t *testing.T) {
 			for i, arg := range fn.Args {
 				testutils.RequireEquals(t, tt.wantInTypes[i], arg)
 			}
-			for outputType, ret := range fn.Ret {
-				testutils.RequireEquals(t, tt.wantOutTypes[outputType], ret)
+			for i, outputType := range fn.Returns {
+				testutils.RequireEquals(t, tt.wantOutTypes[i], outputType)
+				testutils.RequireFalse(t, fn.Ret[i].IsValid())
 			}
 		})
 	}
@@ -298,11 +322,89 @@ func TestFunc_Call(t *testing.T) {
 			got := tt.f.Ret
 			testutils.RequireEquals(t, len(got), len(tt.output))
 
-			i := 0
-			for _, v := range got {
+			for i, v := range got {
 				testutils.RequireEquals(t, v.Interface(), tt.output[i])
-				i++
 			}
 		})
 	}
 }
+
+// TestFunc_CallInterfaceArg tests that interface arguments accept any
+// value which implements them, even without interface inference.
+func TestFunc_CallInterfaceArg(t *testing.T) {
+	takesAnyFn, _ := reflect.WrapFunc(takesAny)
+	testutils.RequireNoError(t, takesAnyFn.Call(false, 42))
+
+	addFn, _ := reflect.WrapFunc(add1)
+	testutils.RequireErrorIs(
+		t, addFn.Call(false, "42"), reflect.ErrInvalidArgType,
+	)
+}
+
+// TestFunc_CallPanic tests that panics in the function are recovered
+// unless panic passthrough is enabled.
+func TestFunc_CallPanic(t *testing.T) {
+	t.Run("recovered", func(t *testing.T) {
+		panicsFn, _ := reflect.WrapFunc(panics)
+
+		var panicErr *reflect.PanicError
+		testutils.RequireTrue(t, errors.As(panicsFn.Call(false), &panicErr))
+		testutils.RequireEquals(t, "something went wrong", panicErr.Value)
+		testutils.RequireTrue(t, len(panicErr.Stack) > 0)
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		panicsFn, _ := reflect.WrapFunc(panics)
+		panicsFn.PanicPassthrough = true
+
+		defer func() {
+			testutils.RequireEquals(t, "something went wrong", recover())
+		}()
+		_ = panicsFn.Call(false)
+		t.Fatalf("expected the call to panic")
+	})
+}
+
+// TestFunc_CallCleanup tests that cleanup functions are stored separately
+// from the other return values.
+func TestFunc_CallCleanup(t *testing.T) {
+	cleanupFn, _ := reflect.WrapFunc(withCleanup)
+	testutils.RequireTrue(t, cleanupFn.HasCleanup)
+	testutils.RequireNoError(t, cleanupFn.Call(false))
+	testutils.RequireEquals(t, 1, cleanupFn.Ret[0].Interface())
+	testutils.RequireTrue(t, cleanupFn.Cleanup.IsValid())
+
+	// A function returning only a func() provides it, rather than
+	// treating it as a cleanup.
+	providesFn, _ := reflect.WrapFunc(func() func() { return func() {} })
+	testutils.RequireFalse(t, providesFn.HasCleanup)
+	testutils.RequireLen(t, providesFn.Ret, 1)
+}
+
+// TestFunc_Clone tests that a cloned Func does not share its return
+// values with the original.
+func TestFunc_Clone(t *testing.T) {
+	addFn, _ := reflect.WrapFunc(add1)
+	testutils.RequireNoError(t, addFn.Call(false, 1))
+
+	clone := addFn.Clone()
+	testutils.RequireFalse(t, clone.Ret[0].IsValid())
+
+	testutils.RequireNoError(t, clone.Call(false, 2))
+	testutils.RequireEquals(t, 3, clone.Ret[0].Interface())
+	testutils.RequireEquals(t, 2, addFn.Ret[0].Interface())
+}
+
+// TestFunc_CallDuplicateReturns tests that return values of the same
+// type are stored in the order they are declared.
+func TestFunc_CallDuplicateReturns(t *testing.T) {
+	pairFn, _ := reflect.WrapFunc(func() (int, string, int) { return 1, "", 2 })
+	testutils.RequireEquals(t, []reflect.Type{
+		reflect.TypeOf(0), reflect.TypeOf(""), reflect.TypeOf(0),
+	}, pairFn.Returns)
+
+	testutils.RequireNoError(t, pairFn.Call(false))
+	testutils.RequireLen(t, pairFn.Ret, 3)
+	testutils.RequireEquals(t, 1, pairFn.Ret[0].Interface())
+	testutils.RequireEquals(t, 2, pairFn.Ret[2].Interface())
+}
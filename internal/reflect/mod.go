@@ -1,26 +1,68 @@
 package reflect
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+)
 
 type (
-	Type  = reflect.Type
-	Value = reflect.Value
+	Type      = reflect.Type
+	Value     = reflect.Value
+	StructTag = reflect.StructTag
 )
 
 var (
 	TypeOf    = reflect.TypeOf
 	ValueOf   = reflect.ValueOf
+	New       = reflect.New
 	MakeFunc  = reflect.MakeFunc
 	MakeSlice = reflect.MakeSlice
+	MakeMap   = reflect.MakeMap
+	SliceOf   = reflect.SliceOf
+
+	// ErrorType is the type of the error interface.
+	ErrorType = TypeOf((*error)(nil)).Elem()
 
 	Interface = reflect.Interface
 	Ptr       = reflect.Ptr
 	Struct    = reflect.Struct
 	Slice     = reflect.Slice
 	Array     = reflect.Array
+	Map       = reflect.Map
+	String    = reflect.String
 )
 
 // IsError returns true if the given type is an error.
 func IsError(t Type) bool {
 	return t.AssignableTo(TypeOf((*error)(nil)).Elem())
 }
+
+// IsFunc returns true if the given type is a function.
+func IsFunc(t Type) bool {
+	return t.Kind() == reflect.Func
+}
+
+// IsContext returns true if the given type is a context.Context.
+func IsContext(t Type) bool {
+	return t == TypeOf((*context.Context)(nil)).Elem()
+}
+
+// IsCleanup returns true if the given type is a func() or a func() error.
+func IsCleanup(t Type) bool {
+	return t.Kind() == reflect.Func && t.NumIn() == 0 &&
+		(t.NumOut() == 0 || (t.NumOut() == 1 && IsError(t.Out(0))))
+}
+
+// IsNil returns true if the given value is invalid, or is a nil value of
+// a type which can be nil.
+func IsNil(v Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Pointer, reflect.Slice, reflect.UnsafePointer:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
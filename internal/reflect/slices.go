@@ -2,12 +2,17 @@ package reflect
 
 import "reflect"
 
-// MakeInitializedSlice creates a slice of the given type with the given
-// values initialized.
+// MakeInitializedSlice creates a slice (or array) of the given type with
+// the given values initialized.
 func MakeInitializedSlice(
 	sliceType reflect.Type, values ...reflect.Value,
 ) reflect.Value {
-	out := reflect.MakeSlice(sliceType, len(values), len(values))
+	var out reflect.Value
+	if sliceType.Kind() == reflect.Array {
+		out = reflect.New(sliceType).Elem()
+	} else {
+		out = reflect.MakeSlice(sliceType, len(values), len(values))
+	}
 	for i, v := range values {
 		out.Index(i).Set(v)
 	}
@@ -2,17 +2,31 @@ package reflect
 
 import (
 	"reflect"
-
-	"github.com/skjdfhkskjds/depinject/internal/utils"
+	"slices"
 )
 
+// StructField is a field of a struct.
+type StructField struct {
+	Name string
+
+	// Index is the index sequence of the field, which has more than one
+	// element if the field belongs to a nested struct.
+	Index []int
+
+	Type     Type
+	Tag      StructTag
+	Exported bool
+	Embedded bool
+}
+
 type StructType struct {
 	Name string
 
 	Type Type
 
-	// Fields is a mapping of field types to their indices.
-	Fields *utils.OrderedMap[Type, int]
+	// Fields are the fields of the struct, in the order they are
+	// declared.
+	Fields []*StructField
 }
 
 func NewStruct(s any) (*StructType, error) {
@@ -22,9 +36,17 @@ func NewStruct(s any) (*StructType, error) {
 	}
 
 	// Loop through each field
-	fields := utils.NewOrderedMap[Type, int]()
+	fields := make([]*StructField, t.NumField())
 	for i := 0; i < t.NumField(); i++ {
-		fields.Set(t.Field(i).Type, i)
+		field := t.Field(i)
+		fields[i] = &StructField{
+			Name:     field.Name,
+			Index:    field.Index,
+			Type:     field.Type,
+			Tag:      field.Tag,
+			Exported: field.IsExported(),
+			Embedded: field.Anonymous,
+		}
 	}
 
 	return &StructType{
@@ -34,38 +56,135 @@ func NewStruct(s any) (*StructType, error) {
 	}, nil
 }
 
-// Constructor returns a function that constructs a new instance of the struct.
+// FieldTypes returns the types of the struct's fields, in order.
+func (s *StructType) FieldTypes() []Type {
+	types := make([]Type, len(s.Fields))
+	for i, field := range s.Fields {
+		types[i] = field.Type
+	}
+	return types
+}
+
+// Constructor returns a function that constructs a new instance of the
+// struct, taking the value of each field as an argument, in order.
 func (s *StructType) Constructor() *Func {
 	return MakeNamedFunc(
-		s.Fields.Keys(),
+		s.FieldTypes(),
 		[]Type{s.Type},
 		func(args []Value) []Value {
-			structValue := reflect.New(s.Type).Elem()
-			for _, arg := range args {
-				index, _ := s.Fields.Get(arg.Type())
-				structValue.Field(index).Set(arg)
-			}
-			return []Value{structValue}
+			return []Value{s.newWithFields(args)}
 		},
 		s.Name,
 	)
 }
 
-// Provider returns a function that takes in an instance of the struct
-// and returns the value of each field as output.
-func (s *StructType) Provider() *Func {
+// PointerConstructor returns a function that constructs a new instance of
+// the struct and returns a pointer to it.
+func (s *StructType) PointerConstructor() *Func {
+	return MakeNamedFunc(
+		s.FieldTypes(),
+		[]Type{reflect.PointerTo(s.Type)},
+		func(args []Value) []Value {
+			return []Value{s.newWithFields(args).Addr()}
+		},
+		s.Name,
+	)
+}
+
+// newWithFields returns an addressable instance of the struct whose
+// fields are set to the given values, in order.
+func (s *StructType) newWithFields(args []Value) Value {
+	structValue := reflect.New(s.Type).Elem()
+	for i, arg := range args {
+		structValue.FieldByIndex(s.Fields[i].Index).Set(arg)
+	}
+	return structValue
+}
+
+// FieldProvider returns a function that takes in an instance of the
+// struct and returns the value of the given field as output. The
+// function's name includes the field's name, so that the providers of
+// different fields of the same type can be told apart.
+func (s *StructType) FieldProvider(field *StructField) *Func {
 	return MakeNamedFunc(
 		[]Type{s.Type},
-		s.Fields.Keys(),
+		[]Type{field.Type},
 		func(args []Value) []Value {
-			structValue := args[0]
-			outputs := make([]Value, 0)
-			for _, t := range s.Fields.Keys() {
-				index, _ := s.Fields.Get(t)
-				outputs = append(outputs, structValue.Field(index))
+			return []Value{args[0].FieldByIndex(field.Index)}
+		},
+		s.Name+"."+field.Name,
+	)
+}
+
+// PointerFieldProvider returns a function like FieldProvider's, which
+// takes in a pointer to an instance of the struct instead. The zero value
+// of the field is returned if the pointer is nil.
+func (s *StructType) PointerFieldProvider(field *StructField) *Func {
+	return MakeNamedFunc(
+		[]Type{reflect.PointerTo(s.Type)},
+		[]Type{field.Type},
+		func(args []Value) []Value {
+			if args[0].IsNil() {
+				return []Value{reflect.Zero(field.Type)}
 			}
-			return outputs
+			return []Value{args[0].Elem().FieldByIndex(field.Index)}
 		},
-		s.Name,
+		s.Name+"."+field.Name,
 	)
 }
+
+// FilterFields removes the fields of the struct for which keep returns
+// false.
+func (s *StructType) FilterFields(keep func(*StructField) bool) {
+	fields := make([]*StructField, 0, len(s.Fields))
+	for _, field := range s.Fields {
+		if keep(field) {
+			fields = append(fields, field)
+		}
+	}
+	s.Fields = fields
+}
+
+// Flatten replaces each struct-typed field of the struct for which expand
+// returns true with the fields of that struct, recursively. Flattened
+// fields are named after the path to them, and their indices are
+// relative to the outer struct.
+func (s *StructType) Flatten(expand func(*StructField) bool) {
+	s.Fields = flattenFields(s.Fields, expand)
+}
+
+func flattenFields(
+	fields []*StructField, expand func(*StructField) bool,
+) []*StructField {
+	flattened := make([]*StructField, 0, len(fields))
+	for _, field := range fields {
+		if field.Type.Kind() != Struct || !expand(field) {
+			flattened = append(flattened, field)
+			continue
+		}
+
+		inner, _ := NewStruct(field.Type)
+		for _, innerField := range flattenFields(inner.Fields, expand) {
+			innerField.Name = field.Name + "." + innerField.Name
+			innerField.Index = append(slices.Clone(field.Index), innerField.Index...)
+			// Exported fields of an embedded struct can be set even if
+			// the struct's type is unexported.
+			innerField.Exported = innerField.Exported &&
+				(field.Exported || field.Embedded)
+			flattened = append(flattened, innerField)
+		}
+	}
+	return flattened
+}
+
+// FieldsWithTag returns the exported fields of the struct which have the
+// given tag, in the order they are declared.
+func (s *StructType) FieldsWithTag(key string) []*StructField {
+	fields := make([]*StructField, 0)
+	for _, field := range s.Fields {
+		if _, ok := field.Tag.Lookup(key); ok && field.Exported {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
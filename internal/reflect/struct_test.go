@@ -23,10 +23,10 @@ func TestNewStruct(t *testing.T) {
 		s, err := reflect.NewStruct(testStructType)
 		testutils.RequireNoError(t, err)
 		testutils.RequireEquals(t, "testStruct", s.Name)
-		testutils.RequireEquals(t, 3, s.Fields.Len())
-		testutils.RequireEquals(t, reflect.TypeOf(""), s.Fields.Keys()[0])
-		testutils.RequireEquals(t, reflect.TypeOf(0), s.Fields.Keys()[1])
-		testutils.RequireEquals(t, reflect.TypeOf(false), s.Fields.Keys()[2])
+		testutils.RequireLen(t, s.Fields, 3)
+		testutils.RequireEquals(t, reflect.TypeOf(""), s.Fields[0].Type)
+		testutils.RequireEquals(t, reflect.TypeOf(0), s.Fields[1].Type)
+		testutils.RequireEquals(t, reflect.TypeOf(false), s.Fields[2].Type)
 	})
 
 	t.Run("not a struct", func(t *testing.T) {
@@ -46,7 +46,7 @@ func TestStruct_Constructor(t *testing.T) {
 
 	result := constructor.Ret
 	testutils.RequireLen(t, result, 1)
-	constructedStruct, ok := result[testStructType].Interface().(testStruct)
+	constructedStruct, ok := result[0].Interface().(testStruct)
 	testutils.RequireTrue(t, ok)
 
 	testutils.RequireEquals(t, "test", constructedStruct.Field1)
@@ -64,30 +64,129 @@ func TestStruct_Constructor_Multiple(t *testing.T) {
 	}
 }
 
-func TestStruct_Provider(t *testing.T) {
+func TestStruct_FieldProvider(t *testing.T) {
 	testStructType := reflect.TypeOf(testStruct{})
 	s, err := reflect.NewStruct(testStructType)
 	testutils.RequireNoError(t, err)
 
-	provider := s.Provider()
-	err = provider.Call(false,
-		testStruct{
-			Field1: "test",
-			Field2: 42,
-			Field3: true,
-		},
-	)
-	testutils.RequireNoError(t, err)
-
-	result := provider.Ret
-	testutils.RequireLen(t, result, 3)
-	testutils.RequireEquals(t, "test", result[reflect.TypeOf("")].Interface())
-	testutils.RequireEquals(t, 42, result[reflect.TypeOf(0)].Interface())
-	testutils.RequireEquals(t, true, result[reflect.TypeOf(false)].Interface())
+	value := testStruct{
+		Field1: "test",
+		Field2: 42,
+		Field3: true,
+	}
+	expected := []any{"test", 42, true}
+	for i, field := range s.Fields {
+		provider := s.FieldProvider(field)
+		testutils.RequireNoError(t, provider.Call(false, value))
+
+		result := provider.Ret
+		testutils.RequireLen(t, result, 1)
+		testutils.RequireEquals(t, expected[i], result[0].Interface())
+	}
 }
 
-func TestStruct_Provider_Multiple(t *testing.T) {
+func TestStruct_FieldProvider_Multiple(t *testing.T) {
 	for i := 0; i < 100; i++ {
-		TestStruct_Provider(t)
+		TestStruct_FieldProvider(t)
 	}
 }
+
+type duplicateStruct struct {
+	First  string
+	Second string
+}
+
+func TestStruct_ConstructorDuplicateTypes(t *testing.T) {
+	s, err := reflect.NewStruct(reflect.TypeOf(duplicateStruct{}))
+	testutils.RequireNoError(t, err)
+
+	// Fields of the same type are set positionally.
+	constructor := s.Constructor()
+	testutils.RequireNoError(t, constructor.Call(false, "first", "second"))
+
+	result, ok := constructor.Ret[0].Interface().(duplicateStruct)
+	testutils.RequireTrue(t, ok)
+	testutils.RequireEquals(t, "first", result.First)
+	testutils.RequireEquals(t, "second", result.Second)
+}
+
+type taggedStruct struct {
+	Field1 string `inject:""`
+	Field2 int
+	Field3 bool `inject:"name,optional"`
+	field4 bool `inject:""`
+}
+
+func TestStruct_FieldsWithTag(t *testing.T) {
+	s, err := reflect.NewStruct(reflect.TypeOf(taggedStruct{}))
+	testutils.RequireNoError(t, err)
+
+	// Only exported fields with the tag are returned, in order.
+	fields := s.FieldsWithTag("inject")
+	testutils.RequireLen(t, fields, 2)
+	testutils.RequireEquals(t, "Field1", fields[0].Name)
+	testutils.RequireEquals(t, 0, fields[0].Index[0])
+	testutils.RequireEquals(t, "", fields[0].Tag.Get("inject"))
+	testutils.RequireEquals(t, "Field3", fields[1].Name)
+	testutils.RequireEquals(t, 2, fields[1].Index[0])
+	testutils.RequireEquals(t, reflect.TypeOf(false), fields[1].Type)
+	testutils.RequireEquals(t, "name,optional", fields[1].Tag.Get("inject"))
+}
+
+func TestStruct_PointerConstructor(t *testing.T) {
+	s, err := reflect.NewStruct(reflect.TypeOf(testStruct{}))
+	testutils.RequireNoError(t, err)
+
+	constructor := s.PointerConstructor()
+	testutils.RequireNoError(t, constructor.Call(false, "test", 42, true))
+
+	result, ok := constructor.Ret[0].Interface().(*testStruct)
+	testutils.RequireTrue(t, ok)
+	testutils.RequireEquals(t, "test", result.Field1)
+	testutils.RequireEquals(t, 42, result.Field2)
+	testutils.RequireEquals(t, true, result.Field3)
+}
+
+func TestStruct_FilterFields(t *testing.T) {
+	s, err := reflect.NewStruct(reflect.TypeOf(taggedStruct{}))
+	testutils.RequireNoError(t, err)
+
+	s.FilterFields(func(field *reflect.StructField) bool {
+		return field.Exported && field.Tag.Get("inject") != ""
+	})
+	testutils.RequireLen(t, s.Fields, 1)
+	testutils.RequireEquals(t, reflect.TypeOf(false), s.Fields[0].Type)
+}
+
+type innerStruct struct {
+	Field2 int
+	Field3 bool
+}
+
+type nestedStruct struct {
+	Field1 string
+	innerStruct
+}
+
+func TestStruct_Flatten(t *testing.T) {
+	s, err := reflect.NewStruct(reflect.TypeOf(nestedStruct{}))
+	testutils.RequireNoError(t, err)
+
+	s.Flatten(func(field *reflect.StructField) bool {
+		return field.Embedded
+	})
+	testutils.RequireLen(t, s.Fields, 3)
+	testutils.RequireEquals(t, "innerStruct.Field2", s.Fields[1].Name)
+	testutils.RequireLen(t, s.Fields[2].Index, 2)
+	testutils.RequireTrue(t, s.Fields[2].Exported)
+
+	// Flattened fields are set through the nested struct.
+	constructor := s.Constructor()
+	testutils.RequireNoError(t, constructor.Call(false, "test", 42, true))
+
+	result, ok := constructor.Ret[0].Interface().(nestedStruct)
+	testutils.RequireTrue(t, ok)
+	testutils.RequireEquals(t, "test", result.Field1)
+	testutils.RequireEquals(t, 42, result.Field2)
+	testutils.RequireEquals(t, true, result.Field3)
+}